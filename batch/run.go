@@ -0,0 +1,195 @@
+package batch
+
+import (
+	"cmp"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"runtime"
+	"slices"
+	"strconv"
+	"sync"
+
+	"example.com/mod/sim"
+)
+
+// Wynik jednego przebiegu (jedna kombinacja, jedno ziarno)
+type RunResult struct {
+	Combo int    // indeks kombinacji (jak w Spec.Combinations)
+	Seed  uint64 // ziarno przebiegu
+	Stats RunStats
+}
+
+// Summary podsumowuje wszystkie przebiegi jednej kombinacji parametrów
+type Summary struct {
+	Values map[string]any
+	Runs   int
+
+	RabbitExtinct     float64 // odsetek przebiegów, w których wyginęły króliki
+	FoxExtinct        float64 // odsetek przebiegów, w których wyginęły lisy
+	RabbitExtinctTurn float64 // średnia tura wyginięcia królików (tylko przebiegi z wyginięciem)
+	FoxExtinctTurn    float64 // średnia tura wyginięcia lisów (tylko przebiegi z wyginięciem)
+
+	RabbitMean, RabbitVar float64 // średnie po przebiegach: średnia i wariancja liczby królików w czasie
+	FoxMean, FoxVar       float64 // to samo dla lisów
+	Period                float64 // średni okres oscylacji (przebiegi, w których go wykryto); 0 = brak
+	PeriodRuns            int     // liczba przebiegów z wykrytym okresem
+}
+
+// Run wykonuje wszystkie przebiegi przeglądu dla kombinacji combos
+// (z Spec.Combinations) równolegle na workers rdzeniach (<= 0 – wszystkich).
+// Po każdym przebiegu wywoływane jest progress (może być nil) z liczbą
+// ukończonych i wszystkich przebiegów. Wyniki nie zależą od liczby rdzeni
+// ani kolejności ukończenia przebiegów.
+func Run(spec Spec, combos []Combination, workers int, progress func(done, total int)) []Summary {
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	type job struct {
+		combo int
+		seed  uint64
+	}
+	jobs := make(chan job)
+	results := make(chan RunResult)
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				results <- runOne(combos[j.combo].Params, j.seed, spec.MaxTurns, j.combo)
+			}
+		}()
+	}
+	total := len(combos) * spec.Seeds
+	go func() {
+		for c := range combos {
+			// Te same ziarna dla każdej kombinacji ułatwiają porównywanie kombinacji
+			for i := range spec.Seeds {
+				jobs <- job{combo: c, seed: spec.FirstSeed + uint64(i)}
+			}
+		}
+		close(jobs)
+		wg.Wait()
+		close(results)
+	}()
+
+	byCombo := make([][]RunResult, len(combos))
+	done := 0
+	for r := range results {
+		byCombo[r.Combo] = append(byCombo[r.Combo], r)
+		done++
+		if progress != nil {
+			progress(done, total)
+		}
+	}
+
+	summaries := make([]Summary, len(combos))
+	for c, runs := range byCombo {
+		// Kolejność ukończenia zależy od planisty, więc sumy liczone są w kolejności ziaren
+		slices.SortFunc(runs, func(a, b RunResult) int { return cmp.Compare(a.Seed, b.Seed) })
+		summaries[c] = summarize(combos[c].Values, runs)
+	}
+	return summaries
+}
+
+// Jeden przebieg do wyginięcia wszystkich zwierząt albo maxTurns tur
+func runOne(p sim.Params, seed uint64, maxTurns, combo int) RunResult {
+	p.Seed = seed
+	world := sim.NewWorldFromParams(p)
+	world.Initialize(p.Rabbits, p.Foxes)
+	for range maxTurns {
+		if pop := world.Step(); pop.Rabbits+pop.Foxes == 0 {
+			break
+		}
+	}
+	return RunResult{Combo: combo, Seed: seed, Stats: Analyze(world.History)}
+}
+
+func summarize(values map[string]any, runs []RunResult) Summary {
+	s := Summary{Values: values, Runs: len(runs)}
+	var rabbitExtinct, foxExtinct int
+	for _, r := range runs {
+		st := r.Stats
+		if st.RabbitExtinctTurn > 0 {
+			rabbitExtinct++
+			s.RabbitExtinctTurn += float64(st.RabbitExtinctTurn)
+		}
+		if st.FoxExtinctTurn > 0 {
+			foxExtinct++
+			s.FoxExtinctTurn += float64(st.FoxExtinctTurn)
+		}
+		s.RabbitMean += st.RabbitMean
+		s.RabbitVar += st.RabbitVar
+		s.FoxMean += st.FoxMean
+		s.FoxVar += st.FoxVar
+		if st.Period > 0 {
+			s.PeriodRuns++
+			s.Period += st.Period
+		}
+	}
+	n := float64(len(runs))
+	s.RabbitExtinct = float64(rabbitExtinct) / n
+	s.FoxExtinct = float64(foxExtinct) / n
+	s.RabbitExtinctTurn = mean(s.RabbitExtinctTurn, rabbitExtinct)
+	s.FoxExtinctTurn = mean(s.FoxExtinctTurn, foxExtinct)
+	s.RabbitMean /= n
+	s.RabbitVar /= n
+	s.FoxMean /= n
+	s.FoxVar /= n
+	s.Period = mean(s.Period, s.PeriodRuns)
+	return s
+}
+
+func mean(sum float64, count int) float64 {
+	if count == 0 {
+		return 0
+	}
+	return sum / float64(count)
+}
+
+// Zapisuje tabelę podsumowań jako CSV: najpierw kolumny przeglądanych
+// parametrów (names), potem statystyki. Puste pole tury wyginięcia
+// oznacza, że gatunek nie wyginął w żadnym przebiegu.
+func WriteSummaryCSV(out io.Writer, names []string, summaries []Summary) error {
+	w := csv.NewWriter(out)
+	header := append(append([]string{}, names...),
+		"runs",
+		"rabbit_extinct", "rabbit_extinct_turn", "fox_extinct", "fox_extinct_turn",
+		"rabbit_mean", "rabbit_var", "fox_mean", "fox_var",
+		"period", "period_runs",
+	)
+	if err := w.Write(header); err != nil {
+		return err
+	}
+	for _, s := range summaries {
+		record := make([]string, 0, len(header))
+		for _, name := range names {
+			record = append(record, fmt.Sprint(s.Values[name]))
+		}
+		extinctTurn := func(frac, turn float64) string {
+			if frac == 0 {
+				return ""
+			}
+			return formatFloat(turn)
+		}
+		record = append(record,
+			strconv.Itoa(s.Runs),
+			formatFloat(s.RabbitExtinct), extinctTurn(s.RabbitExtinct, s.RabbitExtinctTurn),
+			formatFloat(s.FoxExtinct), extinctTurn(s.FoxExtinct, s.FoxExtinctTurn),
+			formatFloat(s.RabbitMean), formatFloat(s.RabbitVar),
+			formatFloat(s.FoxMean), formatFloat(s.FoxVar),
+			formatFloat(s.Period), strconv.Itoa(s.PeriodRuns),
+		)
+		if err := w.Write(record); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'f', 4, 64)
+}
@@ -0,0 +1,190 @@
+// Pakiet batch uruchamia serie symulacji bez okna: przegląd zakresów
+// parametrów, po kilka ziaren na każdą kombinację, równolegle na wszystkich
+// rdzeniach, i podsumowuje wyniki w tabeli.
+package batch
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"slices"
+	"strings"
+
+	"example.com/mod/sim"
+)
+
+// Spec opisuje przegląd parametrów wczytywany z pliku JSON, np.:
+//
+//	{
+//	  "base": {"width": 48, "height": 24},
+//	  "sweep": {
+//	    "growthRate": {"from": 0.02, "to": 0.2, "step": 0.02},
+//	    "foxes": {"values": [2, 5, 10, 20]}
+//	  },
+//	  "seeds": 10,
+//	  "maxTurns": 2000
+//	}
+type Spec struct {
+	Base      json.RawMessage  `json:"base"`      // parametry bazowe (jak w pliku -config), pominięte pola domyślne
+	Sweep     map[string]Range `json:"sweep"`     // zakresy przeglądanych parametrów; klucze jak w pliku parametrów, np. "rabbit.reproduceEnergy"
+	Seeds     int              `json:"seeds"`     // liczba ziaren na kombinację
+	FirstSeed uint64           `json:"firstSeed"` // pierwsze ziarno (domyślnie 1); kolejne przebiegi mają FirstSeed+1, +2, ...
+	MaxTurns  int              `json:"maxTurns"`  // maksymalna długość przebiegu
+}
+
+// Range to lista wartości albo zakres od–do z krokiem (oba końce włącznie)
+type Range struct {
+	Values []any    `json:"values"`
+	From   *float64 `json:"from"`
+	To     *float64 `json:"to"`
+	Step   *float64 `json:"step"`
+}
+
+// Wartości zakresu w kolejności rosnącej (dla from/to/step) lub podanej
+func (r Range) values() ([]any, error) {
+	if len(r.Values) > 0 {
+		if r.From != nil || r.To != nil || r.Step != nil {
+			return nil, fmt.Errorf("podaj albo values, albo from/to/step")
+		}
+		return r.Values, nil
+	}
+	if r.From == nil || r.To == nil || r.Step == nil {
+		return nil, fmt.Errorf("brak values albo kompletu from/to/step")
+	}
+	from, to, step := *r.From, *r.To, *r.Step
+	if step <= 0 || to < from {
+		return nil, fmt.Errorf("nieprawidłowy zakres od %g do %g z krokiem %g", from, to, step)
+	}
+	var vals []any
+	n := int(math.Floor((to-from)/step + 1e-9))
+	for i := 0; i <= n; i++ {
+		// Zaokrąglenie usuwa błędy typu 0.30000000000000004 z etykiet i parametrów
+		vals = append(vals, math.Round((from+float64(i)*step)*1e9)/1e9)
+	}
+	return vals, nil
+}
+
+// Wczytuje i sprawdza plik przeglądu; zwraca też jego kombinacje, bo ich
+// wyliczenie jest zarazem sprawdzeniem zakresów i parametrów
+func LoadSpec(path string) (Spec, []Combination, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Spec{}, nil, err
+	}
+	var spec Spec
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&spec); err != nil {
+		return Spec{}, nil, fmt.Errorf("%s: nieprawidłowy plik przeglądu: %w", path, err)
+	}
+	if spec.Seeds <= 0 {
+		spec.Seeds = 1
+	}
+	if spec.FirstSeed == 0 {
+		spec.FirstSeed = 1
+	}
+	if spec.MaxTurns <= 0 {
+		return Spec{}, nil, fmt.Errorf("%s: maxTurns musi być większe od 0", path)
+	}
+	combos, err := spec.Combinations()
+	if err != nil {
+		return Spec{}, nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return spec, combos, nil
+}
+
+// Combination to jedna kombinacja przeglądanych parametrów
+type Combination struct {
+	Values map[string]any // wartości przeglądanych parametrów
+	Params sim.Params     // pełne parametry (bez ziarna)
+}
+
+// Names zwraca nazwy przeglądanych parametrów w stałej kolejności
+func (s Spec) Names() []string {
+	names := make([]string, 0, len(s.Sweep))
+	for name := range s.Sweep {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Wszystkie kombinacje (iloczyn kartezjański zakresów), sprawdzone pod kątem poprawności
+func (s Spec) Combinations() ([]Combination, error) {
+	base := sim.DefaultParams()
+	if len(s.Base) > 0 {
+		var err error
+		if base, err = sim.ParseParams(s.Base); err != nil {
+			return nil, fmt.Errorf("base: %w", err)
+		}
+	}
+
+	combos := []map[string]any{{}}
+	for _, name := range s.Names() {
+		vals, err := s.Sweep[name].values()
+		if err != nil {
+			return nil, fmt.Errorf("sweep.%s: %w", name, err)
+		}
+		var next []map[string]any
+		for _, c := range combos {
+			for _, v := range vals {
+				m := make(map[string]any, len(c)+1)
+				for k, old := range c {
+					m[k] = old
+				}
+				m[name] = v
+				next = append(next, m)
+			}
+		}
+		combos = next
+	}
+
+	result := make([]Combination, 0, len(combos))
+	for _, values := range combos {
+		p, err := withValues(base, values)
+		if err != nil {
+			return nil, fmt.Errorf("kombinacja %v: %w", values, err)
+		}
+		result = append(result, Combination{Values: values, Params: p})
+	}
+	return result, nil
+}
+
+// Ustawia parametry o nazwach z pliku parametrów (z kropkami dla pól
+// zagnieżdżonych) przez JSON, więc obsługiwany jest każdy parametr Params
+func withValues(base sim.Params, values map[string]any) (sim.Params, error) {
+	data, err := json.Marshal(base)
+	if err != nil {
+		return sim.Params{}, err
+	}
+	var tree map[string]any
+	if err := json.Unmarshal(data, &tree); err != nil {
+		return sim.Params{}, err
+	}
+	for name, v := range values {
+		if name == "seed" {
+			return sim.Params{}, fmt.Errorf("ziarna ustawia się polami seeds i firstSeed, nie przez sweep")
+		}
+		node := tree
+		parts := strings.Split(name, ".")
+		for _, part := range parts[:len(parts)-1] {
+			child, ok := node[part].(map[string]any)
+			if !ok {
+				return sim.Params{}, fmt.Errorf("nieznany parametr %q", name)
+			}
+			node = child
+		}
+		last := parts[len(parts)-1]
+		if _, ok := node[last]; !ok {
+			return sim.Params{}, fmt.Errorf("nieznany parametr %q", name)
+		}
+		node[last] = v
+	}
+	data, err = json.Marshal(tree)
+	if err != nil {
+		return sim.Params{}, err
+	}
+	return sim.ParseParams(data)
+}
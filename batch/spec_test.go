@@ -0,0 +1,81 @@
+package batch_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"example.com/mod/batch"
+	"example.com/mod/sim"
+)
+
+func ptr(v float64) *float64 { return &v }
+
+func TestCombinationsCartesianProduct(t *testing.T) {
+	spec := batch.Spec{
+		Base: json.RawMessage(`{"width": 20, "height": 10}`),
+		Sweep: map[string]batch.Range{
+			"growthRate":             {From: ptr(0.1), To: ptr(0.3), Step: ptr(0.1)},
+			"foxes":                  {Values: []any{2.0, 5.0}},
+			"rabbit.reproduceEnergy": {Values: []any{12.0}},
+		},
+	}
+	combos, err := spec.Combinations()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := spec.Names(), []string{"foxes", "growthRate", "rabbit.reproduceEnergy"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("nazwy %v, oczekiwano %v", got, want)
+	}
+
+	// Kolejność: ostatni parametr (według nazw) zmienia się najszybciej
+	want := []struct {
+		foxes  int
+		growth float64
+	}{{2, 0.1}, {2, 0.2}, {2, 0.3}, {5, 0.1}, {5, 0.2}, {5, 0.3}}
+	if len(combos) != len(want) {
+		t.Fatalf("%d kombinacji, oczekiwano %d", len(combos), len(want))
+	}
+	for i, w := range want {
+		p := combos[i].Params
+		if p.Foxes != w.foxes || p.GrowthRate != w.growth || p.Rabbit.ReproduceEnergy != 12 {
+			t.Errorf("kombinacja %d: foxes %d, growthRate %v, reproduceEnergy %v; oczekiwano %d, %v, 12",
+				i, p.Foxes, p.GrowthRate, p.Rabbit.ReproduceEnergy, w.foxes, w.growth)
+		}
+		if p.Width != 20 || p.Height != 10 || p.Rabbits != sim.DefaultParams().Rabbits {
+			t.Errorf("kombinacja %d: parametry bazowe nie zostały zachowane: %+v", i, p)
+		}
+		if combos[i].Values["growthRate"] != w.growth {
+			t.Errorf("kombinacja %d: etykieta growthRate %v, oczekiwano %v", i, combos[i].Values["growthRate"], w.growth)
+		}
+	}
+}
+
+func TestCombinationsWithoutSweep(t *testing.T) {
+	combos, err := batch.Spec{}.Combinations()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(combos) != 1 || !reflect.DeepEqual(combos[0].Params, sim.DefaultParams()) {
+		t.Fatalf("oczekiwano jednej kombinacji z parametrami domyślnymi, jest %+v", combos)
+	}
+}
+
+func TestCombinationsErrors(t *testing.T) {
+	tests := map[string]batch.Spec{
+		"nieznany parametr":            {Sweep: map[string]batch.Range{"speed": {Values: []any{1.0}}}},
+		"nieznane pole zagnieżdżone":   {Sweep: map[string]batch.Range{"rabbit.speed": {Values: []any{1.0}}}},
+		"ziarno":                       {Sweep: map[string]batch.Range{"seed": {Values: []any{1.0}}}},
+		"values i from/to/step":        {Sweep: map[string]batch.Range{"foxes": {Values: []any{1.0}, From: ptr(1)}}},
+		"niekompletny zakres":          {Sweep: map[string]batch.Range{"foxes": {From: ptr(1), To: ptr(5)}}},
+		"zakres malejący":              {Sweep: map[string]batch.Range{"foxes": {From: ptr(5), To: ptr(1), Step: ptr(1)}}},
+		"krok zerowy":                  {Sweep: map[string]batch.Range{"foxes": {From: ptr(1), To: ptr(5), Step: ptr(0)}}},
+		"nieprawidłowa wartość":        {Sweep: map[string]batch.Range{"growthRate": {Values: []any{2.0}}}},
+		"nieprawidłowe parametry bazy": {Base: json.RawMessage(`{"width": 1}`)},
+	}
+	for name, spec := range tests {
+		if _, err := spec.Combinations(); err == nil {
+			t.Errorf("%s: oczekiwano błędu", name)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package batch
+
+import "example.com/mod/sim"
+
+// RunStats to statystyki jednego przebiegu liczone z historii populacji
+type RunStats struct {
+	Turns             int `json:"turns"`             // liczba tur przebiegu
+	RabbitExtinctTurn int `json:"rabbitExtinctTurn"` // tura, w której wyginęły króliki; 0 = przetrwały
+	FoxExtinctTurn    int `json:"foxExtinctTurn"`    // tura, w której wyginęły lisy; 0 = przetrwały
+
+	// Średnia i wariancja liczby zwierząt w czasie
+	RabbitMean float64 `json:"rabbitMean"`
+	RabbitVar  float64 `json:"rabbitVar"`
+	FoxMean    float64 `json:"foxMean"`
+	FoxVar     float64 `json:"foxVar"`
+	Period     float64 `json:"period"` // okres oscylacji liczby królików w turach; 0 = nie wykryto
+}
+
+// Minimalna autokorelacja, od której szczyt uznawany jest za oscylację
+const minPeriodCorrelation = 0.2
+
+// Analyze liczy statystyki przebiegu z historii populacji. Średnie
+// i wariancje dotyczą tur do końca przebiegu, także po wyginięciu gatunku.
+func Analyze(history []sim.Population) RunStats {
+	st := RunStats{Turns: len(history)}
+	rabbits := make([]float64, len(history))
+	foxes := make([]float64, len(history))
+	for i, p := range history {
+		rabbits[i] = float64(p.Rabbits)
+		foxes[i] = float64(p.Foxes)
+		if p.Rabbits == 0 && st.RabbitExtinctTurn == 0 {
+			st.RabbitExtinctTurn = p.Turn
+		}
+		if p.Foxes == 0 && st.FoxExtinctTurn == 0 {
+			st.FoxExtinctTurn = p.Turn
+		}
+	}
+	st.RabbitMean, st.RabbitVar = meanVar(rabbits)
+	st.FoxMean, st.FoxVar = meanVar(foxes)
+	st.Period = period(rabbits)
+	return st
+}
+
+func meanVar(xs []float64) (mean, variance float64) {
+	if len(xs) == 0 {
+		return 0, 0
+	}
+	for _, x := range xs {
+		mean += x
+	}
+	mean /= float64(len(xs))
+	for _, x := range xs {
+		variance += (x - mean) * (x - mean)
+	}
+	return mean, variance / float64(len(xs))
+}
+
+// Szacuje okres oscylacji z autokorelacji: pierwszy szczyt po tym, jak
+// autokorelacja spadnie poniżej zera. Zwraca 0, gdy szereg jest za krótki,
+// stały albo szczyt jest zbyt słaby.
+func period(xs []float64) float64 {
+	mean, variance := meanVar(xs)
+	if len(xs) < 8 || variance == 0 {
+		return 0
+	}
+	acf := func(lag int) float64 {
+		var sum float64
+		for i := 0; i+lag < len(xs); i++ {
+			sum += (xs[i] - mean) * (xs[i+lag] - mean)
+		}
+		return sum / (float64(len(xs)) * variance)
+	}
+
+	maxLag := len(xs) / 2
+	lag := 1
+	for lag < maxLag && acf(lag) >= 0 {
+		lag++
+	}
+	best, bestLag := 0.0, 0
+	for ; lag < maxLag; lag++ {
+		c := acf(lag)
+		if c > best {
+			best, bestLag = c, lag
+		} else if bestLag > 0 && c < 0 {
+			break
+		}
+	}
+	if best < minPeriodCorrelation {
+		return 0
+	}
+	return float64(bestLag)
+}
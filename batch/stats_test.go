@@ -0,0 +1,94 @@
+package batch_test
+
+import (
+	"math"
+	"testing"
+
+	"example.com/mod/batch"
+	"example.com/mod/sim"
+)
+
+// Historia z podanych liczebności królików i lisów, tury od 1
+func history(rabbits, foxes []int) []sim.Population {
+	h := make([]sim.Population, len(rabbits))
+	for i := range h {
+		h[i] = sim.Population{Turn: i + 1, Rabbits: rabbits[i], Foxes: foxes[i]}
+	}
+	return h
+}
+
+// Oscylacja liczby królików o podanym okresie
+func oscillation(turns, period int) []int {
+	xs := make([]int, turns)
+	for i := range xs {
+		xs[i] = 50 + int(math.Round(40*math.Sin(2*math.Pi*float64(i)/float64(period))))
+	}
+	return xs
+}
+
+func constant(turns, v int) []int {
+	xs := make([]int, turns)
+	for i := range xs {
+		xs[i] = v
+	}
+	return xs
+}
+
+func TestAnalyzeExtinction(t *testing.T) {
+	tests := []struct {
+		name                string
+		rabbits, foxes      []int
+		rabbitTurn, foxTurn int
+	}{
+		{"oba przetrwały", []int{5, 6, 7}, []int{2, 2, 1}, 0, 0},
+		{"lisy wyginęły", []int{5, 6, 7, 8}, []int{2, 1, 0, 0}, 0, 3},
+		{"oba wyginęły", []int{3, 1, 0, 0}, []int{2, 2, 1, 0}, 3, 4},
+		// Liczy się pierwsza tura bez zwierząt gatunku
+		{"wyginięcie od startu", []int{0, 0}, []int{1, 0}, 1, 2},
+		{"pusta historia", nil, nil, 0, 0},
+	}
+	for _, tt := range tests {
+		st := batch.Analyze(history(tt.rabbits, tt.foxes))
+		if st.Turns != len(tt.rabbits) || st.RabbitExtinctTurn != tt.rabbitTurn || st.FoxExtinctTurn != tt.foxTurn {
+			t.Errorf("%s: tury %d, wyginięcie królików %d, lisów %d; oczekiwano %d, %d, %d", tt.name,
+				st.Turns, st.RabbitExtinctTurn, st.FoxExtinctTurn, len(tt.rabbits), tt.rabbitTurn, tt.foxTurn)
+		}
+	}
+}
+
+func TestAnalyzeMeanAndVariance(t *testing.T) {
+	st := batch.Analyze(history([]int{2, 4, 4, 4, 5, 5, 7, 9}, constant(8, 3)))
+	if st.RabbitMean != 5 || st.RabbitVar != 4 {
+		t.Errorf("króliki: średnia %v, wariancja %v; oczekiwano 5 i 4", st.RabbitMean, st.RabbitVar)
+	}
+	if st.FoxMean != 3 || st.FoxVar != 0 {
+		t.Errorf("lisy: średnia %v, wariancja %v; oczekiwano 3 i 0", st.FoxMean, st.FoxVar)
+	}
+}
+
+func TestAnalyzePeriod(t *testing.T) {
+	tests := []struct {
+		name    string
+		rabbits []int
+		want    float64
+	}{
+		{"okres 20", oscillation(200, 20), 20},
+		{"okres 37", oscillation(400, 37), 37},
+		{"stała liczebność", constant(200, 30), 0},
+		{"za krótki szereg", []int{1, 5, 1, 5, 1}, 0},
+		// Monotoniczny spadek nie jest oscylacją
+		{"trend", func() []int {
+			xs := make([]int, 200)
+			for i := range xs {
+				xs[i] = 200 - i
+			}
+			return xs
+		}(), 0},
+	}
+	for _, tt := range tests {
+		st := batch.Analyze(history(tt.rabbits, constant(len(tt.rabbits), 1)))
+		if st.Period != tt.want {
+			t.Errorf("%s: okres %v, oczekiwano %v", tt.name, st.Period, tt.want)
+		}
+	}
+}
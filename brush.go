@@ -0,0 +1,87 @@
+package main
+
+import (
+	"fmt"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+
+	"example.com/mod/sim"
+)
+
+// Pędzel do edycji planszy myszą. B włącza i wyłącza pędzel, klawisze 1–8
+// wybierają narzędzie, [ i ] zmieniają promień. Lewy przycisk maluje
+// wybranym narzędziem, prawy – gumką.
+type Brush struct {
+	Active bool
+	Tool   sim.BrushTool
+	Radius int
+
+	last    [2]int // ostatnio malowane pole, aby przeciąganie nie malowało go w każdej klatce
+	painted bool
+}
+
+const maxBrushRadius = 10
+
+var brushToolLabels = map[sim.BrushTool]string{
+	sim.ToolRabbit:      "króliki",
+	sim.ToolFox:         "lisy",
+	sim.ToolGrassShort:  "trawa niska",
+	sim.ToolGrassMedium: "trawa średnia",
+	sim.ToolGrassTall:   "trawa wysoka",
+	sim.ToolBare:        "bez trawy",
+	sim.ToolObstacle:    "przeszkody",
+	sim.ToolErase:       "gumka",
+}
+
+// Obsługa klawiszy pędzla
+func (b *Brush) HandleKeys() {
+	if rl.IsKeyPressed(rl.KeyB) {
+		b.Active = !b.Active
+	}
+	if !b.Active {
+		return
+	}
+	for i := range sim.BrushTools {
+		if rl.IsKeyPressed(rl.KeyOne + int32(i)) {
+			b.Tool = sim.BrushTool(i)
+		}
+	}
+	if rl.IsKeyPressed(rl.KeyLeftBracket) {
+		b.Radius = max(0, b.Radius-1)
+	}
+	if rl.IsKeyPressed(rl.KeyRightBracket) {
+		b.Radius = min(maxBrushRadius, b.Radius+1)
+	}
+}
+
+// Maluje pole (x, y) przez runner (między turami), gdy wciśnięty jest
+// przycisk myszy; zwraca liczbę zmienionych pól
+func (b *Brush) Apply(runner *sim.Runner, x, y int) int {
+	tool := b.Tool
+	switch {
+	case rl.IsMouseButtonDown(rl.MouseButtonLeft):
+	case rl.IsMouseButtonDown(rl.MouseButtonRight):
+		tool = sim.ToolErase
+	default:
+		b.painted = false
+		return 0
+	}
+	if b.painted && b.last == [2]int{x, y} {
+		return 0
+	}
+	b.last, b.painted = [2]int{x, y}, true
+	changed := 0
+	runner.Do(func(w *sim.World) { changed = w.Paint(x, y, b.Radius, tool) })
+	return changed
+}
+
+// Zarys pędzla wokół pola (x, y); rysowany w układzie planszy (w widoku kamery)
+func (b *Brush) DrawCursor(w *sim.World, cellSize int, x, y int) {
+	cx, cy := w.CellCenter(x, y)
+	r := (float32(b.Radius) + 0.5) * float32(cellSize)
+	rl.DrawCircleLines(int32(cx*float64(cellSize)), int32(cy*float64(cellSize)), r, rl.Yellow)
+}
+
+func (b *Brush) Label() string {
+	return fmt.Sprintf("Pędzel (B): %s [1–8], promień %d [ ], PPM – gumka", brushToolLabels[b.Tool], b.Radius)
+}
@@ -0,0 +1,181 @@
+package main
+
+import (
+	rl "github.com/gen2brain/raylib-go/raylib"
+
+	"example.com/mod/sim"
+)
+
+// Widok planszy w oknie: kamera raylib z przybliżaniem kółkiem myszy,
+// przesuwaniem (przeciąganie środkowym przyciskiem lub strzałki),
+// dopasowaniem do okna (klawisz 0) i minimapą, gdy widać tylko część planszy
+type Viewport struct {
+	Camera rl.Camera2D
+	Area   rl.Rectangle // część okna, w której rysowana jest plansza
+
+	board rl.Vector2 // rozmiar planszy w pikselach (przy przybliżeniu 1)
+}
+
+const (
+	maxZoom      = 8
+	zoomStep     = 1.1
+	panSpeed     = 12  // piksele ekranu na klatkę przy przesuwaniu strzałkami
+	minimapSize  = 180 // dłuższy bok minimapy w pikselach
+	minimapBlock = 2   // najmniejszy blok minimapy w pikselach
+)
+
+// Tworzy widok planszy o rozmiarze boardW x boardH pikseli dopasowany do area
+func NewViewport(area rl.Rectangle, boardW, boardH int) *Viewport {
+	v := &Viewport{Area: area, board: rl.NewVector2(float32(boardW), float32(boardH))}
+	v.Fit()
+	return v
+}
+
+// Dopasowuje przybliżenie tak, aby cała plansza mieściła się w oknie
+func (v *Viewport) Fit() {
+	v.Camera.Zoom = v.fitZoom()
+	v.Camera.Offset = rl.NewVector2(v.Area.X+v.Area.Width/2, v.Area.Y+v.Area.Height/2)
+	v.Camera.Target = rl.NewVector2(v.board.X/2, v.board.Y/2)
+}
+
+func (v *Viewport) fitZoom() float32 {
+	return min(v.Area.Width/v.board.X, v.Area.Height/v.board.Y)
+}
+
+// Widoczny fragment planszy w pikselach planszy
+func (v *Viewport) Visible() rl.Rectangle {
+	tl := rl.GetScreenToWorld2D(rl.NewVector2(v.Area.X, v.Area.Y), v.Camera)
+	br := rl.GetScreenToWorld2D(rl.NewVector2(v.Area.X+v.Area.Width, v.Area.Y+v.Area.Height), v.Camera)
+	return rl.NewRectangle(tl.X, tl.Y, br.X-tl.X, br.Y-tl.Y)
+}
+
+// Punkt planszy (w pikselach planszy) pod punktem ekranu; false, gdy punkt
+// leży poza obszarem planszy w oknie albo na minimapie
+func (v *Viewport) ToBoard(screen rl.Vector2) (rl.Vector2, bool) {
+	if !rl.CheckCollisionPointRec(screen, v.Area) {
+		return rl.Vector2{}, false
+	}
+	if mm, ok := v.minimapRect(); ok && rl.CheckCollisionPointRec(screen, mm) {
+		return rl.Vector2{}, false
+	}
+	return rl.GetScreenToWorld2D(screen, v.Camera), true
+}
+
+// Obsługa przybliżania, przesuwania, dopasowania i kliknięć w minimapę
+func (v *Viewport) HandleInput() {
+	mouse := rl.GetMousePosition()
+	if wheel := rl.GetMouseWheelMove(); wheel != 0 && rl.CheckCollisionPointRec(mouse, v.Area) {
+		// Przybliżanie wokół punktu pod kursorem
+		v.Camera.Target = rl.GetScreenToWorld2D(mouse, v.Camera)
+		v.Camera.Offset = mouse
+		zoom := v.Camera.Zoom
+		if wheel > 0 {
+			zoom *= zoomStep
+		} else {
+			zoom /= zoomStep
+		}
+		v.Camera.Zoom = max(v.fitZoom()/2, min(zoom, maxZoom))
+	}
+	if rl.IsMouseButtonDown(rl.MouseButtonMiddle) {
+		delta := rl.GetMouseDelta()
+		v.Camera.Target.X -= delta.X / v.Camera.Zoom
+		v.Camera.Target.Y -= delta.Y / v.Camera.Zoom
+	}
+	step := panSpeed / v.Camera.Zoom
+	if rl.IsKeyDown(rl.KeyLeft) {
+		v.Camera.Target.X -= step
+	}
+	if rl.IsKeyDown(rl.KeyRight) {
+		v.Camera.Target.X += step
+	}
+	if rl.IsKeyDown(rl.KeyUp) {
+		v.Camera.Target.Y -= step
+	}
+	if rl.IsKeyDown(rl.KeyDown) {
+		v.Camera.Target.Y += step
+	}
+	if rl.IsKeyPressed(rl.KeyZero) || rl.IsKeyPressed(rl.KeyKp0) {
+		v.Fit()
+	}
+	if mm, ok := v.minimapRect(); ok && rl.IsMouseButtonDown(rl.MouseButtonLeft) && rl.CheckCollisionPointRec(mouse, mm) {
+		v.centerOn(rl.NewVector2((mouse.X-mm.X)/mm.Width*v.board.X, (mouse.Y-mm.Y)/mm.Height*v.board.Y))
+	}
+	v.clamp()
+}
+
+// Ustawia środek widoku na punkcie planszy p
+func (v *Viewport) centerOn(p rl.Vector2) {
+	v.Camera.Offset = rl.NewVector2(v.Area.X+v.Area.Width/2, v.Area.Y+v.Area.Height/2)
+	v.Camera.Target = p
+}
+
+// Nie pozwala odsunąć planszy całkowicie poza okno: punkt pod środkiem
+// obszaru planszy zostaje na planszy
+func (v *Viewport) clamp() {
+	center := rl.GetScreenToWorld2D(rl.NewVector2(v.Area.X+v.Area.Width/2, v.Area.Y+v.Area.Height/2), v.Camera)
+	v.Camera.Target.X -= center.X - max(0, min(center.X, v.board.X))
+	v.Camera.Target.Y -= center.Y - max(0, min(center.Y, v.board.Y))
+}
+
+// Rozpoczyna rysowanie w układzie planszy, obcięte do obszaru planszy
+func (v *Viewport) Begin() {
+	rl.BeginScissorMode(int32(v.Area.X), int32(v.Area.Y), int32(v.Area.Width), int32(v.Area.Height))
+	rl.BeginMode2D(v.Camera)
+}
+
+func (v *Viewport) End() {
+	rl.EndMode2D()
+	rl.EndScissorMode()
+}
+
+// Położenie minimapy w prawym dolnym rogu obszaru planszy; false, gdy
+// widać całą planszę i minimapa nie jest potrzebna
+func (v *Viewport) minimapRect() (rl.Rectangle, bool) {
+	vis := v.Visible()
+	if vis.X <= 0 && vis.Y <= 0 && vis.X+vis.Width >= v.board.X && vis.Y+vis.Height >= v.board.Y {
+		return rl.Rectangle{}, false
+	}
+	scale := minimapSize / max(v.board.X, v.board.Y)
+	w, h := v.board.X*scale, v.board.Y*scale
+	return rl.NewRectangle(v.Area.X+v.Area.Width-w-10, v.Area.Y+v.Area.Height-h-10, w, h), true
+}
+
+// Rysuje minimapę: bloki pól w kolorze lisa, królika (gdy w bloku jest
+// takie zwierzę) albo podłoża, oraz ramkę widocznego fragmentu
+func (v *Viewport) DrawMinimap(w *sim.World) {
+	mm, ok := v.minimapRect()
+	if !ok {
+		return
+	}
+	cols := min(w.Width, int(mm.Width)/minimapBlock)
+	rows := min(w.Height, int(mm.Height)/minimapBlock)
+	bw, bh := mm.Width/float32(cols), mm.Height/float32(rows)
+	for by := range rows {
+		for bx := range cols {
+			x0, x1 := bx*w.Width/cols, (bx+1)*w.Width/cols
+			y0, y1 := by*w.Height/rows, (by+1)*w.Height/rows
+			color := groundColors[w.Grid[(y0+y1)/2][(x0+x1)/2].Ground]
+		block:
+			for y := y0; y < y1; y++ {
+				for x := x0; x < x1; x++ {
+					switch w.Grid[y][x].Animal {
+					case sim.Fox:
+						color = foxColor
+						break block
+					case sim.Rabbit:
+						color = rabbitColor
+					}
+				}
+			}
+			rl.DrawRectangleRec(rl.NewRectangle(mm.X+float32(bx)*bw, mm.Y+float32(by)*bh, bw+1, bh+1), color)
+		}
+	}
+	rl.DrawRectangleLinesEx(mm, 1, rl.DarkGray)
+
+	vis := v.Visible()
+	scale := mm.Width / v.board.X
+	frame := rl.NewRectangle(mm.X+vis.X*scale, mm.Y+vis.Y*scale, vis.Width*scale, vis.Height*scale)
+	rl.BeginScissorMode(int32(mm.X), int32(mm.Y), int32(mm.Width)+1, int32(mm.Height)+1)
+	rl.DrawRectangleLinesEx(frame, 2, rl.Red)
+	rl.EndScissorMode()
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"fmt"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+
+	"example.com/mod/sim"
+)
+
+// LiveChart rysuje w oknie wykres populacji z ostatnich tur bezpośrednio
+// prymitywami raylib. Zwierzęta mają oś po lewej, trawa (liczba pól z trawą)
+// – po prawej, bo bywa jej o rząd wielkości więcej.
+type LiveChart struct {
+	Window int // liczba ostatnich tur widocznych na wykresie
+}
+
+// Serie wykresu: kolor, nazwa w legendzie i wartość z tury
+type chartSeries struct {
+	name  string
+	color rl.Color
+	value func(p sim.Population) int
+	right bool // na prawej osi
+}
+
+var liveChartSeries = []chartSeries{
+	{"Króliki", rl.NewColor(90, 90, 200, 255), func(p sim.Population) int { return p.Rabbits }, false},
+	{"Lisy", rl.NewColor(220, 110, 30, 255), func(p sim.Population) int { return p.Foxes }, false},
+	{"Trawa", rl.NewColor(70, 160, 60, 255), func(p sim.Population) int { return p.GrassShort + p.GrassMedium + p.GrassTall }, true},
+}
+
+const chartFontSize = 14
+
+// Rysuje wykres w prostokącie area
+func (c LiveChart) Draw(history []sim.Population, area rl.Rectangle) {
+	rl.DrawRectangleRec(area, rl.RayWhite)
+	rl.DrawRectangleLinesEx(area, 1, rl.LightGray)
+
+	// Marginesy na opisy osi
+	plotArea := rl.NewRectangle(area.X+44, area.Y+8, area.Width-44-44, area.Height-8-22)
+	if plotArea.Width <= 0 || plotArea.Height <= 0 {
+		return
+	}
+
+	start := max(0, len(history)-c.Window)
+	visible := history[start:]
+	leftMax, rightMax := 1, 1
+	for _, p := range visible {
+		for _, s := range liveChartSeries {
+			if s.right {
+				rightMax = max(rightMax, s.value(p))
+			} else {
+				leftMax = max(leftMax, s.value(p))
+			}
+		}
+	}
+
+	// Linie pomocnicze i opisy osi Y
+	for i := 0; i <= 2; i++ {
+		y := plotArea.Y + plotArea.Height*float32(2-i)/2
+		rl.DrawLineV(rl.NewVector2(plotArea.X, y), rl.NewVector2(plotArea.X+plotArea.Width, y), rl.Fade(rl.LightGray, 0.6))
+		left := fmt.Sprint(leftMax * i / 2)
+		rl.DrawText(left, int32(plotArea.X)-6-rl.MeasureText(left, chartFontSize), int32(y)-chartFontSize/2, chartFontSize, rl.DarkGray)
+		rl.DrawText(fmt.Sprint(rightMax*i/2), int32(plotArea.X+plotArea.Width)+6, int32(y)-chartFontSize/2, chartFontSize, liveChartSeries[2].color)
+	}
+
+	// Opisy osi X: pierwsza i ostatnia widoczna tura
+	if len(visible) > 0 {
+		first, last := fmt.Sprint(visible[0].Turn), fmt.Sprintf("Tura %d", visible[len(visible)-1].Turn)
+		labelY := int32(plotArea.Y+plotArea.Height) + 4
+		rl.DrawText(first, int32(plotArea.X), labelY, chartFontSize, rl.DarkGray)
+		rl.DrawText(last, int32(plotArea.X+plotArea.Width)-rl.MeasureText(last, chartFontSize), labelY, chartFontSize, rl.DarkGray)
+	}
+
+	// Oś X ma stałą szerokość okna, więc wykres przewija się po jego zapełnieniu
+	step := plotArea.Width / float32(max(1, c.Window-1))
+	for _, s := range liveChartSeries {
+		top := leftMax
+		if s.right {
+			top = rightMax
+		}
+		for i := 1; i < len(visible); i++ {
+			p0 := rl.NewVector2(plotArea.X+float32(i-1)*step, plotArea.Y+plotArea.Height*(1-float32(s.value(visible[i-1]))/float32(top)))
+			p1 := rl.NewVector2(plotArea.X+float32(i)*step, plotArea.Y+plotArea.Height*(1-float32(s.value(visible[i]))/float32(top)))
+			rl.DrawLineEx(p0, p1, 2, s.color)
+		}
+	}
+
+	// Legenda w lewym górnym rogu wykresu
+	x := int32(plotArea.X) + 8
+	for _, s := range liveChartSeries {
+		rl.DrawRectangle(x, int32(plotArea.Y)+4, 12, 12, s.color)
+		rl.DrawText(s.name, x+16, int32(plotArea.Y)+3, chartFontSize, rl.DarkGray)
+		x += 16 + rl.MeasureText(s.name, chartFontSize) + 14
+	}
+}
@@ -0,0 +1,212 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"gonum.org/v1/plot/vg"
+
+	"example.com/mod/sim"
+)
+
+// Ustawienia przebiegu wspólne dla trybu okienkowego i trybu bez okna
+type RunOptions struct {
+	MaxTurns     int     // maksymalna liczba tur bez okna; <= 0 oznacza do wyginięcia zwierząt
+	TPS          float64 // początkowe tempo w oknie i terminalu (tury na sekundę)
+	HistoryPath  string  // plik CSV ze statystykami tur, pusty = bez zapisu
+	JSONLPath    string  // plik JSON Lines ze statystykami tur, pusty = bez zapisu
+	PlotPath     string  // plik PNG z wykresem, pusty = bez zapisu
+	ParamsPath   string  // plik JSON z parametrami i ziarnem, pusty = bez zapisu
+	LoadPath     string  // zrzut, od którego zaczyna się symulacja, pusty = nowy świat
+	SnapshotPath string  // plik zrzutu stanu (końcowego bez okna, F5/F9 w oknie)
+	AnimalsPath  string  // plik CSV z metrykami zwierząt, pusty = bez zapisu
+	EventsPath   string  // plik JSON Lines ze zdarzeniami (narodziny, śmierć, jedzenie, ruch), pusty = bez zapisu
+	SnapshotDir  string  // katalog zrzutów zapisywanych przez API serwera, pusty = zapis wyłączony
+}
+
+// Tworzy nowy świat z parametrów albo, gdy podano loadPath, wczytuje go ze zrzutu.
+// Zwraca też parametry świata (przy zrzucie – zapisane w zrzucie).
+func createWorld(params sim.Params, loadPath string) (*sim.World, sim.Params, error) {
+	if loadPath == "" {
+		world := sim.NewWorldFromParams(params)
+		world.Initialize(params.Rabbits, params.Foxes)
+		return world, params, nil
+	}
+
+	world, err := sim.LoadSnapshot(loadPath)
+	if err != nil {
+		return nil, params, fmt.Errorf("wczytanie zrzutu: %w", err)
+	}
+	return world, world.Params(), nil
+}
+
+// Uruchamia symulację bez okna i zapisuje wyniki na dysk
+func RunHeadless(params sim.Params, opts RunOptions) error {
+	world, params, err := createWorld(params, opts.LoadPath)
+	if err != nil {
+		return err
+	}
+
+	history, err := openHistoryOutput(opts)
+	if err != nil {
+		return err
+	}
+	defer history.Close()
+	history.Attach(world)
+	// Historia wczytana ze zrzutu trafia do plików przed nowymi turami
+	if err := history.Write(world.History...); err != nil {
+		return err
+	}
+
+	for turn := 0; opts.MaxTurns <= 0 || turn < opts.MaxTurns; turn++ {
+		pop := world.Step()
+		if err := history.Write(pop); err != nil {
+			return err
+		}
+		if pop.Rabbits+pop.Foxes == 0 {
+			break
+		}
+	}
+	if err := history.Close(); err != nil {
+		return err
+	}
+	return saveResults(world, params, opts)
+}
+
+// Wypisuje podsumowanie przebiegu i zapisuje jego wyniki wskazane w opcjach
+// (parametry, zrzut, metryki zwierząt, wykres)
+func saveResults(world *sim.World, params sim.Params, opts RunOptions) error {
+	animals := sim.CountAnimals(world)
+	fmt.Printf("Tury: %d  Króliki: %d  Lisy: %d  Ziarno: %d\n", world.Turn, animals[sim.Rabbit], animals[sim.Fox], params.Seed)
+
+	if opts.ParamsPath != "" {
+		if err := sim.SaveParams(opts.ParamsPath, params); err != nil {
+			return fmt.Errorf("zapis parametrów: %w", err)
+		}
+	}
+	if opts.SnapshotPath != "" {
+		if err := sim.SaveSnapshot(opts.SnapshotPath, world); err != nil {
+			return fmt.Errorf("zapis zrzutu: %w", err)
+		}
+	}
+	if opts.AnimalsPath != "" {
+		if err := sim.SaveAnimalsCSV(opts.AnimalsPath, world); err != nil {
+			return fmt.Errorf("zapis metryk zwierząt: %w", err)
+		}
+	}
+	if opts.PlotPath != "" {
+		if err := SavePlot(opts.PlotPath, world.History, 8*vg.Inch, 4*vg.Inch); err != nil {
+			return fmt.Errorf("zapis wykresu: %w", err)
+		}
+	}
+	return nil
+}
+
+// Pliki, do których na bieżąco trafiają statystyki kolejnych tur i zdarzenia
+type historyOutput struct {
+	files      []*os.File // pliki pisarzy statystyk, a na końcu – dziennika zdarzeń
+	writers    []sim.PopulationWriter
+	newWriters []func(f *os.File) sim.PopulationWriter // tworzą pisarzy od nowa (Restart)
+	events     *sim.EventLog                           // nil, gdy zdarzenia nie są zapisywane
+}
+
+// Otwiera pliki CSV i JSON Lines wskazane w opcjach (puste ścieżki są pomijane)
+func openHistoryOutput(opts RunOptions) (*historyOutput, error) {
+	out := &historyOutput{}
+	for _, o := range []struct {
+		path   string
+		writer func(f *os.File) sim.PopulationWriter
+	}{
+		{opts.HistoryPath, func(f *os.File) sim.PopulationWriter { return sim.NewCSVWriter(f) }},
+		{opts.JSONLPath, func(f *os.File) sim.PopulationWriter { return sim.NewJSONLWriter(f) }},
+	} {
+		if o.path == "" {
+			continue
+		}
+		f, err := os.Create(o.path)
+		if err != nil {
+			out.Close()
+			return nil, fmt.Errorf("zapis historii: %w", err)
+		}
+		out.files = append(out.files, f)
+		out.writers = append(out.writers, o.writer(f))
+		out.newWriters = append(out.newWriters, o.writer)
+	}
+	if opts.EventsPath != "" {
+		f, err := os.Create(opts.EventsPath)
+		if err != nil {
+			out.Close()
+			return nil, fmt.Errorf("zapis zdarzeń: %w", err)
+		}
+		out.files = append(out.files, f)
+		out.events = sim.NewEventLog(f)
+	}
+	return out, nil
+}
+
+// Zapisuje zdarzenia świata w dzienniku; wywoływane dla każdego nowego
+// świata (także wczytanego ze zrzutu), bo kopie nie przejmują obserwatorów
+func (h *historyOutput) Attach(w *sim.World) {
+	if h.events != nil {
+		w.Subscribe(h.events.Handle)
+	}
+}
+
+// Zaczyna pliki od nowa dla świata wczytanego ze zrzutu: dotychczasowa
+// zawartość jest usuwana, a historia zapisana w zrzucie trafia do plików
+// statystyk, więc numery tur się nie cofają i nie mieszają dwóch przebiegów
+func (h *historyOutput) Restart(w *sim.World) error {
+	for _, f := range h.files {
+		if err := f.Truncate(0); err != nil {
+			return fmt.Errorf("zapis historii: %w", err)
+		}
+		if _, err := f.Seek(0, io.SeekStart); err != nil {
+			return fmt.Errorf("zapis historii: %w", err)
+		}
+	}
+	for i, newWriter := range h.newWriters {
+		h.writers[i] = newWriter(h.files[i])
+	}
+	if h.events != nil {
+		h.events = sim.NewEventLog(h.files[len(h.files)-1])
+	}
+	h.Attach(w)
+	return h.Write(w.History...)
+}
+
+// Zapisuje statystyki tur i od razu opróżnia bufory, aby pliki można było czytać w trakcie symulacji
+func (h *historyOutput) Write(pops ...sim.Population) error {
+	for _, w := range h.writers {
+		for _, p := range pops {
+			if err := w.Write(p); err != nil {
+				return fmt.Errorf("zapis historii: %w", err)
+			}
+		}
+		if err := w.Flush(); err != nil {
+			return fmt.Errorf("zapis historii: %w", err)
+		}
+	}
+	if h.events != nil {
+		if err := h.events.Flush(); err != nil {
+			return fmt.Errorf("zapis zdarzeń: %w", err)
+		}
+	}
+	return nil
+}
+
+func (h *historyOutput) Close() error {
+	var firstErr error
+	if h.events != nil {
+		if err := h.events.Flush(); err != nil {
+			firstErr = fmt.Errorf("zapis zdarzeń: %w", err)
+		}
+	}
+	for _, f := range h.files {
+		if err := f.Close(); err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("zapis historii: %w", err)
+		}
+	}
+	h.files, h.writers, h.newWriters, h.events = nil, nil, nil, nil
+	return firstErr
+}
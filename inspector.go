@@ -0,0 +1,130 @@
+package main
+
+import (
+	"fmt"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+
+	"example.com/mod/sim"
+)
+
+// Panel inspektora: stan pola i zwierzęcia, pola w zasięgu wzroku oraz
+// podgląd akcji w następnej turze. Pokazuje zaznaczone pole (kliknięcie),
+// a po włączeniu klawiszem I także pole pod kursorem.
+type Inspector struct {
+	Hover bool
+
+	// Podgląd liczony jest raz dla danego stanu świata i pola
+	world *sim.World
+	pos   [2]int
+	last  sim.Inspection
+}
+
+var ruleLabels = map[string]string{
+	"flee":       "ucieczka przed drapieżnikiem",
+	"graze":      "jedzenie trawy",
+	"seek_grass": "szukanie trawy",
+	"hunt":       "polowanie",
+	"stalk":      "skradanie się do królika",
+	"mate":       "rozmnażanie",
+	"seek_mate":  "szukanie partnera",
+	"wander":     "wędrówka",
+	"":           "zostaje na miejscu",
+}
+
+const inspectorFontSize = 18
+
+// Aktualny opis pola (x, y) w świecie w
+func (in *Inspector) inspect(w *sim.World, x, y int) (sim.Inspection, bool) {
+	if in.world != w || in.pos != [2]int{x, y} {
+		insp, ok := w.Inspect(x, y)
+		if !ok {
+			return sim.Inspection{}, false
+		}
+		in.world, in.pos, in.last = w, [2]int{x, y}, insp
+	}
+	return in.last, true
+}
+
+// Zaznacza pola widoczne z (x, y); rysowane w układzie planszy (w widoku kamery)
+func (in *Inspector) DrawMarks(w *sim.World, cellSize int, x, y int) {
+	insp, ok := in.inspect(w, x, y)
+	if !ok {
+		return
+	}
+	for _, v := range insp.Visible {
+		cx, cy := w.CellCenter(v[0], v[1])
+		rl.DrawCircle(int32(cx*float64(cellSize)), int32(cy*float64(cellSize)), float32(cellSize)*0.3, rl.Fade(rl.Yellow, 0.5))
+	}
+	cx, cy := w.CellCenter(x, y)
+	rl.DrawCircleLines(int32(cx*float64(cellSize)), int32(cy*float64(cellSize)), float32(cellSize)*0.5, rl.Orange)
+}
+
+// Rysuje panel z opisem pola (x, y) przy prawej krawędzi okna o szerokości width
+func (in *Inspector) DrawPanel(w *sim.World, x, y int, width int) {
+	insp, ok := in.inspect(w, x, y)
+	if !ok {
+		return
+	}
+	lines := inspectionLines(w, insp)
+	textWidth := int32(0)
+	for _, l := range lines {
+		textWidth = max(textWidth, rl.MeasureText(l, inspectorFontSize))
+	}
+	panelX := int32(width) - textWidth - 30
+	rl.DrawRectangle(panelX, 130, textWidth+20, int32(len(lines))*(inspectorFontSize+4)+16, rl.Fade(rl.RayWhite, 0.9))
+	rl.DrawRectangleLines(panelX, 130, textWidth+20, int32(len(lines))*(inspectorFontSize+4)+16, rl.Gray)
+	for i, l := range lines {
+		rl.DrawText(l, panelX+10, 138+int32(i)*(inspectorFontSize+4), inspectorFontSize, rl.Black)
+	}
+}
+
+// Wiersze tekstu panelu inspektora
+func inspectionLines(w *sim.World, insp sim.Inspection) []string {
+	c := insp.Cell
+	lines := []string{describeCell(w, insp.Pos[0], insp.Pos[1])}
+	if c.Animal == sim.Empty {
+		return append(lines, fmt.Sprintf("Sąsiednie pola: %d", len(insp.Visible)))
+	}
+	name := "Królik"
+	if c.Animal == sim.Fox {
+		name = "Lis"
+	}
+	lines = append(lines,
+		fmt.Sprintf("%s #%d", name, c.ID),
+		fmt.Sprintf("Energia: %.2f  Wiek: %d", c.Energy, c.Age),
+		fmt.Sprintf("Cooldown rozmnażania: %d", c.ReproduceCooldown),
+	)
+	lines = append(lines, fmt.Sprintf("Pola w zasięgu wzroku: %d", len(insp.Visible)))
+
+	lines = append(lines, "Następna tura: "+ruleLabels[insp.Rule])
+	for _, e := range insp.Events {
+		lines = append(lines, "  "+describeEvent(e))
+	}
+	return lines
+}
+
+// Opis zdarzenia z punktu widzenia zwierzęcia, którego dotyczy
+func describeEvent(e sim.Event) string {
+	switch e.Kind {
+	case sim.EventMove:
+		return fmt.Sprintf("ruch na (%d, %d)", e.Pos[0], e.Pos[1])
+	case sim.EventBirth:
+		return fmt.Sprintf("rodzi młode #%d na (%d, %d)", e.Animal, e.Pos[0], e.Pos[1])
+	case sim.EventEat:
+		if e.Food == sim.Rabbit {
+			return fmt.Sprintf("zjada królika #%d na (%d, %d)", e.Other, e.Pos[0], e.Pos[1])
+		}
+		return fmt.Sprintf("zjada trawę na (%d, %d), +%.2f energii", e.Pos[0], e.Pos[1], e.Energy)
+	case sim.EventDeath:
+		switch e.Cause {
+		case sim.CausePredation:
+			return fmt.Sprintf("zostaje zjedzony przez #%d", e.Other)
+		case sim.CauseStarvation:
+			return "umiera z głodu"
+		case sim.CauseOldAge:
+			return "umiera ze starości"
+		}
+	}
+	return e.Kind.String()
+}
@@ -1,13 +1,19 @@
 package main
 
 import (
+	"errors"
+	"flag"
 	"fmt"
-	"math/rand"
+	"image/color"
+	"os"
 	"runtime"
+	"strings"
 	"time"
 
 	rl "github.com/gen2brain/raylib-go/raylib"
 
+	"example.com/mod/sim"
+
 	"os/exec"
 
 	"gonum.org/v1/plot"
@@ -15,697 +21,486 @@ import (
 	"gonum.org/v1/plot/vg"
 )
 
-type SimParams struct {
-	Width, Height  int
-	Rabbits, Foxes int
-	GrowthRate     float64
+// Losowe ziarno z zegara, gdy użytkownik nie podał własnego
+func randomSeed() uint64 {
+	return uint64(time.Now().UnixNano())
 }
 
-func ShowMenu() SimParams {
-    rl.InitWindow(480, 320, "Ustawienia symulacji")
-    defer rl.CloseWindow()
-    rl.SetTargetFPS(60)
-
-    params := SimParams{Width: 32, Height: 16, Rabbits: 12, Foxes: 6, GrowthRate: 0.1}
-    selected := 0
-    options := []string{"Szerokość", "Wysokość", "Króliki", "Lisy", "Wzrost trawy", "Start"}
-
-    // Wczytaj teksturę lisa do menu
-    foxMenuTexture := rl.LoadTexture("fox.png")
-    defer rl.UnloadTexture(foxMenuTexture)
-
-    for !rl.WindowShouldClose() {
-        rl.BeginDrawing()
-        rl.ClearBackground(rl.RayWhite)
-
-        // Rysuj lisa w tle menu (np. na środku, lekko przezroczysty)
-        foxScale := float32(3.0)
-        foxX := float32(240 - int(foxMenuTexture.Width)*int(foxScale)/2)
-        foxY := float32(60)
-        rl.DrawTextureEx(foxMenuTexture, rl.NewVector2(foxX, foxY), 0, foxScale, rl.Fade(rl.White, 0.18))
-
-        rl.DrawText("Menu symulacji", 120, 20, 28, rl.Black)
-
-        for i, opt := range options {
-            color := rl.Black
-            if i == selected {
-                color = rl.Red
-            }
-            val := ""
-            switch i {
-            case 0:
-                val = fmt.Sprintf("%d", params.Width)
-            case 1:
-                val = fmt.Sprintf("%d", params.Height)
-            case 2:
-                val = fmt.Sprintf("%d", params.Rabbits)
-            case 3:
-                val = fmt.Sprintf("%d", params.Foxes)
-            case 4:
-                val = fmt.Sprintf("%.2f", params.GrowthRate)
-            }
-            rl.DrawText(fmt.Sprintf("%s: %s", opt, val), 80, int32(70+30*i), 24, color)
-        }
-
-        rl.DrawText("Strzałki: wybór/opcja, Enter: start", 40, 270, 18, rl.Gray)
-        rl.EndDrawing()
-
-        if rl.IsKeyPressed(rl.KeyDown) {
-            selected = (selected + 1) % len(options)
-        }
-        if rl.IsKeyPressed(rl.KeyUp) {
-            selected = (selected - 1 + len(options)) % len(options)
-        }
-        if selected < 5 {
-            if rl.IsKeyPressed(rl.KeyRight) {
-                switch selected {
-                case 0:
-                    params.Width += 2
-                case 1:
-                    params.Height += 2
-                case 2:
-                    params.Rabbits++
-                case 3:
-                    params.Foxes++
-                case 4:
-                    params.GrowthRate += 0.01
-                }
-            }
-            if rl.IsKeyPressed(rl.KeyLeft) {
-                switch selected {
-                case 0:
-                    if params.Width > 4 {
-                        params.Width -= 2
-                    }
-                case 1:
-                    if params.Height > 4 {
-                        params.Height -= 2
-                    }
-                case 2:
-                    if params.Rabbits > 1 {
-                        params.Rabbits--
-                    }
-                case 3:
-                    if params.Foxes > 1 {
-                        params.Foxes--
-                    }
-                case 4:
-                    if params.GrowthRate > 0.01 {
-                        params.GrowthRate -= 0.01
-                    }
-                }
-            }
-        }
-        if selected == 5 && rl.IsKeyPressed(rl.KeyEnter) {
-            break
-        }
-    }
-    return params
-}
+func ShowMenu(params sim.Params) sim.Params {
+	rl.InitWindow(480, 400, "Ustawienia symulacji")
+	defer rl.CloseWindow()
+	rl.SetTargetFPS(60)
+
+	selected := 0
+	options := []string{"Szerokość", "Wysokość", "Króliki", "Lisy", "Wzrost trawy", "Ziarno", "Topologia", "Start"}
+	// Pierwszy problem z parametrami po próbie startu; menu nie startuje z
+	// parametrami, których nie przyjmuje Validate (np. hex z odbijającymi krawędziami)
+	invalid := ""
+
+	// Wczytaj teksturę lisa do menu
+	foxMenuTexture := rl.LoadTexture("fox.png")
+	defer rl.UnloadTexture(foxMenuTexture)
+
+	for !rl.WindowShouldClose() {
+		rl.BeginDrawing()
+		rl.ClearBackground(rl.RayWhite)
+
+		// Rysuj lisa w tle menu (np. na środku, lekko przezroczysty)
+		foxScale := float32(3.0)
+		foxX := float32(240 - int(foxMenuTexture.Width)*int(foxScale)/2)
+		foxY := float32(60)
+		rl.DrawTextureEx(foxMenuTexture, rl.NewVector2(foxX, foxY), 0, foxScale, rl.Fade(rl.White, 0.18))
+
+		rl.DrawText("Menu symulacji", 120, 20, 28, rl.Black)
+
+		for i, opt := range options {
+			color := rl.Black
+			if i == selected {
+				color = rl.Red
+			}
+			val := ""
+			switch i {
+			case 0:
+				val = fmt.Sprintf("%d", params.Width)
+			case 1:
+				val = fmt.Sprintf("%d", params.Height)
+			case 2:
+				val = fmt.Sprintf("%d", params.Rabbits)
+			case 3:
+				val = fmt.Sprintf("%d", params.Foxes)
+			case 4:
+				val = fmt.Sprintf("%.2f", params.GrowthRate)
+			case 5:
+				val = fmt.Sprintf("%d", params.Seed)
+			case 6:
+				val = params.Topology.String()
+			}
+			rl.DrawText(fmt.Sprintf("%s: %s", opt, val), 80, int32(70+30*i), 24, color)
+		}
 
-type Cell struct {
-    Ground            int     // 0=brak trawy, 1=short, 2=medium, 3=tall
-    Animal            int     // 0=empty, 4=rabbit, 5=fox
-    Energy            float64
-    ReproduceCooldown int
-    Age               int
+		if invalid != "" {
+			size := int32(16)
+			for size > 10 && rl.MeasureText(invalid, size) > 440 {
+				size--
+			}
+			rl.DrawText(invalid, 20, 312, size, rl.Red)
+		}
+		rl.DrawText("Strzałki: wybór/opcja, Enter: start", 40, 340, 18, rl.Gray)
+		rl.DrawText("R: losowe ziarno", 40, 365, 18, rl.Gray)
+		rl.EndDrawing()
+
+		if rl.IsKeyPressed(rl.KeyDown) {
+			selected = (selected + 1) % len(options)
+		}
+		if rl.IsKeyPressed(rl.KeyUp) {
+			selected = (selected - 1 + len(options)) % len(options)
+		}
+		if rl.IsKeyPressed(rl.KeyR) {
+			params.Seed = randomSeed()
+		}
+		if rl.IsKeyPressed(rl.KeyRight) || rl.IsKeyPressed(rl.KeyLeft) {
+			invalid = ""
+		}
+		if selected < 7 {
+			if rl.IsKeyPressed(rl.KeyRight) {
+				switch selected {
+				case 0:
+					params.Width += 2
+				case 1:
+					params.Height += 2
+				case 2:
+					params.Rabbits++
+				case 3:
+					params.Foxes++
+				case 4:
+					params.GrowthRate += 0.01
+				case 5:
+					params.Seed++
+				case 6:
+					params.Topology = params.Topology.Next()
+				}
+			}
+			if rl.IsKeyPressed(rl.KeyLeft) {
+				switch selected {
+				case 0:
+					if params.Width > 4 {
+						params.Width -= 2
+					}
+				case 1:
+					if params.Height > 4 {
+						params.Height -= 2
+					}
+				case 2:
+					if params.Rabbits > 1 {
+						params.Rabbits--
+					}
+				case 3:
+					if params.Foxes > 1 {
+						params.Foxes--
+					}
+				case 4:
+					if params.GrowthRate > 0.01 {
+						params.GrowthRate -= 0.01
+					}
+				case 5:
+					if params.Seed > 1 {
+						params.Seed--
+					}
+				case 6:
+					params.Topology = params.Topology.Prev()
+				}
+			}
+		}
+		if selected == 7 && rl.IsKeyPressed(rl.KeyEnter) {
+			err := params.Validate()
+			if err == nil {
+				break
+			}
+			invalid, _, _ = strings.Cut(err.Error(), "\n")
+		}
+	}
+	return params
 }
 
-const (
-    empty  = 0
-    grassShort = 1
-    grassMedium = 2
-    grassTall = 3
-    rabbit = 4
-    fox    = 5
-
-    rabbitReproduceEnergy = 14.0
-    foxReproduceEnergy    = 28.0
-
-    rabbitCooldown = 6
-    foxCooldown    = 10
-)
+// Zmiana tempa klawiszami +/- (mnożnik tur na sekundę)
+const tpsFactor = 1.5
 
-var (
-    texEmpty      rl.Texture2D
-    texGrassShort rl.Texture2D
-    texGrassMed   rl.Texture2D
-    texGrassTall  rl.Texture2D
-    texRabbit     rl.Texture2D
-    texFox        rl.Texture2D
-)
+// Czas wyświetlania komunikatu w klatkach (3 s przy 60 klatkach na sekundę)
+const statusDuration = 180
 
-func loadTextures() {
-    texEmpty = rl.LoadTexture("empty.png")
-    texGrassShort = rl.LoadTexture("grass_short.png")
-    texGrassMed = rl.LoadTexture("grass_medium.png")
-    texGrassTall = rl.LoadTexture("grass_tall.png")
-    texRabbit = rl.LoadTexture("rabbit.png")
-    texFox = rl.LoadTexture("fox.png")
-}
+// Największy obszar planszy w oknie; większa plansza nie mieści się w całości
+// przy rozmiarze pola 32 pikseli i jest oglądana przez kamerę
+const (
+	maxBoardViewWidth  = 1280
+	maxBoardViewHeight = 720
+)
 
-func unloadTextures() {
-    rl.UnloadTexture(texEmpty)
-    rl.UnloadTexture(texGrassShort)
-    rl.UnloadTexture(texGrassMed)
-    rl.UnloadTexture(texGrassTall)
-    rl.UnloadTexture(texRabbit)
-    rl.UnloadTexture(texFox)
-}
+// Okno pokazuje planszę o rozmiarze boardWidth x boardHeight pikseli (przy
+// większej planszy – jej fragment widziany przez kamerę), a pod nią wykres
+// o wysokości plotPreviewHeight
+func SimulateWithVisualization(w *sim.World, renderer *SwitchRenderer, cellSize int, boardWidth, boardHeight int, plotPreviewHeight int, opts RunOptions) {
+	// Okno odświeża się niezależnie od tempa symulacji ustawianego w runnerze
+	rl.SetTargetFPS(60)
+	renderState := w.Copy()
 
-type World struct {
-	Grid       [][]Cell
-	Width      int
-	Height     int
-	MaxGrass   int
-	GrowthRate float64
-}
+	history, err := openHistoryOutput(opts)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "błąd:", err)
+		history = &historyOutput{}
+	}
+	if err := history.Write(w.History...); err != nil {
+		fmt.Fprintln(os.Stderr, "błąd:", err)
+	}
+	history.Attach(w)
 
-func NewWorld(width, height, maxGrass int, growthRate float64) *World {
-	grid := make([][]Cell, height)
-	for i := range grid {
-		grid[i] = make([]Cell, width)
-		for j := range grid[i] {
-			grid[i][j] = Cell{Ground: empty, Animal: empty}
+	runner := sim.NewRunner(w, opts.TPS)
+	runner.OnStep = func(w *sim.World, pop sim.Population) {
+		if err := history.Write(pop); err != nil {
+			fmt.Fprintln(os.Stderr, "błąd:", err)
+			history.Close()
 		}
 	}
-	return &World{
-		Grid:       grid,
-		Width:      width,
-		Height:     height,
-		MaxGrass:   maxGrass,
-		GrowthRate: growthRate,
+	// Świat wczytany ze zrzutu podmieniany jest w gorutynie symulacji między
+	// turami; pliki statystyk i zdarzeń zaczynają się wtedy od nowa
+	runner.OnLoad = func(w *sim.World) {
+		if err := history.Restart(w); err != nil {
+			fmt.Fprintln(os.Stderr, "błąd:", err)
+			history.Close()
+		}
 	}
-}
+	quitChan := make(chan struct{})
+	runDone := make(chan struct{})
+	go func() {
+		defer close(runDone)
+		defer history.Close()
+		runner.Run(quitChan)
+	}()
+	chart := LiveChart{Window: 200}
 
-// Inicjalizacja planszy z losowym rozmieszczeniem trawy, królików i lisów
-func (w *World) Initialize(rabbitCount, foxCount int) {
-    rand.Seed(time.Now().UnixNano())
-
-    for y := 0; y < w.Height; y++ {
-        for x := 0; x < w.Width; x++ {
-            r := rand.Float64()
-            if r < 0.33 {
-                w.Grid[y][x].Ground = grassShort
-            } else if r < 0.66 {
-                w.Grid[y][x].Ground = grassMedium
-            } else {
-                w.Grid[y][x].Ground = grassTall
-            }
-        }
-    }
-
-    for i := 0; i < rabbitCount; i++ {
-        x, y := rand.Intn(w.Width), rand.Intn(w.Height)
-        w.Grid[y][x].Animal = rabbit
-        w.Grid[y][x].Energy = 10.0
-    }
-
-    for i := 0; i < foxCount; i++ {
-        x, y := rand.Intn(w.Width), rand.Intn(w.Height)
-        w.Grid[y][x].Animal = fox
-        w.Grid[y][x].Energy = 20.0
-    }
-}
+	snapshotPath := opts.SnapshotPath
+	if snapshotPath == "" {
+		snapshotPath = "stan.kls"
+	}
+	status := ""
+	statusFrames := 0
+	var followed uint64 // zwierzę śledzone po kliknięciu; 0 = żadne
+	var selected [2]int // pole zaznaczone kliknięciem, gdy nie ma na nim zwierzęcia
+	hasSelected := false
+	var inspector Inspector
+	brush := Brush{Tool: sim.ToolFox}
+	bw, bh := boardPixels(w, cellSize)
+	viewport := NewViewport(rl.NewRectangle(0, 0, float32(boardWidth), float32(boardHeight)), bw, bh)
 
-func (w *World) DrawWorld(cellSize int) {
-	for y := 0; y < w.Height; y++ {
-		for x := 0; x < w.Width; x++ {
-			pos := rl.NewVector2(float32(x*cellSize), float32(y*cellSize))
-			rl.DrawTextureEx(texEmpty, pos, 0, float32(cellSize)/float32(texEmpty.Width), rl.White)
-			switch w.Grid[y][x].Ground {
-			case grassShort:
-				rl.DrawTextureEx(texGrassShort, pos, 0, float32(cellSize)/float32(texGrassShort.Width), rl.White)
-			case grassMedium:
-				rl.DrawTextureEx(texGrassMed, pos, 0, float32(cellSize)/float32(texGrassMed.Width), rl.White)
-			case grassTall:
-				rl.DrawTextureEx(texGrassTall, pos, 0, float32(cellSize)/float32(texGrassTall.Width), rl.White)
+loop:
+	for !rl.WindowShouldClose() {
+		// Spacja – pauza, N – jedna tura w pauzie, +/- – tempo, F – przewijanie
+		if rl.IsKeyPressed(rl.KeySpace) {
+			runner.TogglePause()
+		}
+		if rl.IsKeyPressed(rl.KeyN) || rl.IsKeyPressedRepeat(rl.KeyN) {
+			runner.Step(1)
+		}
+		if rl.IsKeyPressed(rl.KeyEqual) || rl.IsKeyPressed(rl.KeyKpAdd) {
+			runner.SetTPS(runner.State().TPS * tpsFactor)
+		}
+		if rl.IsKeyPressed(rl.KeyMinus) || rl.IsKeyPressed(rl.KeyKpSubtract) {
+			runner.SetTPS(runner.State().TPS / tpsFactor)
+		}
+		if rl.IsKeyPressed(rl.KeyF) {
+			runner.SetFastForward(!runner.State().FastForward)
+		}
+		brush.HandleKeys()
+		if rl.IsKeyPressed(rl.KeyI) {
+			inspector.Hover = !inspector.Hover
+		}
+		if rl.IsKeyPressed(rl.KeyR) {
+			renderer.Toggle()
+			status = "Rysowanie: tekstury pól (R)"
+			if renderer.UsePixel {
+				status = "Rysowanie: piksele (R)"
 			}
-			if w.Grid[y][x].Animal == rabbit {
-				rl.DrawTextureEx(texRabbit, pos, 0, float32(cellSize)/float32(texRabbit.Width), rl.White)
-			} else if w.Grid[y][x].Animal == fox {
-				rl.DrawTextureEx(texFox, pos, 0, float32(cellSize)/float32(texFox.Width), rl.White)
+			statusFrames = statusDuration
+		}
+
+		// F5 zapisuje wyświetlany stan, F9 wczytuje go z powrotem
+		if rl.IsKeyPressed(rl.KeyF5) {
+			if err := sim.SaveSnapshot(snapshotPath, renderState); err != nil {
+				status = fmt.Sprintf("Błąd zapisu: %v", err)
+			} else {
+				status = fmt.Sprintf("Zapisano %s (tura %d)", snapshotPath, renderState.Turn)
 			}
+			statusFrames = statusDuration
+		}
+		if rl.IsKeyPressed(rl.KeyF9) {
+			if loaded, err := loadForVisualization(snapshotPath, renderState, runner); err != nil {
+				status = fmt.Sprintf("Błąd wczytywania: %v", err)
+			} else {
+				status = fmt.Sprintf("Wczytano %s (tura %d)", snapshotPath, loaded.Turn)
+			}
+			statusFrames = statusDuration
 		}
-	}
-}
 
-func neighbors(x, y, width, height int) [][2]int {
-	var result [][2]int
-	for dx := -1; dx <= 1; dx++ {
-		for dy := -1; dy <= 1; dy++ {
-			if dx == 0 && dy == 0 {
-				continue
+		select {
+		case newState, ok := <-runner.Updates():
+			if ok {
+				renderState = newState
+			} else {
+				break loop
 			}
-			nx, ny := x+dx, y+dy
-			if nx >= 0 && nx < width && ny >= 0 && ny < height {
-				result = append(result, [2]int{nx, ny})
+		default:
+		}
+
+		viewport.HandleInput()
+
+		// Pole pod kursorem (w układzie planszy, z uwzględnieniem kamery)
+		var hoverX, hoverY int
+		hovering := false
+		if p, ok := viewport.ToBoard(rl.GetMousePosition()); ok {
+			hoverX, hoverY, hovering = pickCell(renderState, cellSize, p)
+		}
+		if hovering {
+			if brush.Active {
+				brush.Apply(runner, hoverX, hoverY)
+			} else if rl.IsMouseButtonPressed(rl.MouseButtonLeft) {
+				// Lewy przycisk zaznacza pole i zaczyna śledzić zwierzę, prawy kończy
+				followed = renderState.Grid[hoverY][hoverX].ID
+				selected, hasSelected = [2]int{hoverX, hoverY}, followed == 0
 			}
 		}
-	}
-	return result
-}
+		if !brush.Active && rl.IsMouseButtonPressed(rl.MouseButtonRight) {
+			followed, hasSelected = 0, false
+		}
 
-func (w *World) Copy() *World {
-	newGrid := make([][]Cell, w.Height)
-	for y := 0; y < w.Height; y++ {
-		newGrid[y] = make([]Cell, w.Width)
-		copy(newGrid[y], w.Grid[y])
-	}
-	return &World{
-		Grid:       newGrid,
-		Width:      w.Width,
-		Height:     w.Height,
-		MaxGrass:   w.MaxGrass,
-		GrowthRate: w.GrowthRate,
-	}
-}
+		// Pole opisywane przez inspektora: śledzone zwierzę, zaznaczone pole
+		// albo (po włączeniu klawiszem I) pole pod kursorem
+		inspected, inspecting := selected, hasSelected
+		if inspector.Hover && !inspecting && hovering {
+			inspected, inspecting = [2]int{hoverX, hoverY}, true
+		}
+		followedPos, followedAlive := [2]int{}, false
+		if followed != 0 {
+			followedPos, followedAlive = renderState.Locate(followed)
+			inspected, inspecting = followedPos, followedAlive
+		}
 
-func (w *World) GrowGrass() {
-    for y := 0; y < w.Height; y++ {
-        for x := 0; x < w.Width; x++ {
-            if w.Grid[y][x].Ground == empty {
-                hasGrassNeighbor := false
-                for _, n := range neighbors(x, y, w.Width, w.Height) {
-                    if w.Grid[n[1]][n[0]].Ground > empty {
-                        hasGrassNeighbor = true
-                        break
-                    }
-                }
-                if hasGrassNeighbor && rand.Float64() < w.GrowthRate {
-                    w.Grid[y][x].Ground = grassShort
-                }
-            } else if w.Grid[y][x].Ground == grassShort {
-                if rand.Float64() < w.GrowthRate {
-                    w.Grid[y][x].Ground = grassMedium
-                }
-            } else if w.Grid[y][x].Ground == grassMedium {
-                if rand.Float64() < w.GrowthRate {
-                    w.Grid[y][x].Ground = grassTall
-                }
-            }
-        }
-    }
-}
+		rl.BeginDrawing()
+		rl.ClearBackground(rl.RayWhite)
 
-func (w *World) MoveRabbits() {
-    newGrid := w.Copy().Grid
-
-    coords := make([][2]int, 0, w.Width*w.Height)
-    for y := 0; y < w.Height; y++ {
-        for x := 0; x < w.Width; x++ {
-            coords = append(coords, [2]int{x, y})
-        }
-    }
-    rand.Shuffle(len(coords), func(i, j int) { coords[i], coords[j] = coords[j], coords[i] })
-
-    for _, pos := range coords {
-        x, y := pos[0], pos[1]
-        cell := w.Grid[y][x]
-        if cell.Animal == rabbit && cell.ReproduceCooldown == 0 {
-            ns := neighbors(x, y, w.Width, w.Height)
-            done := false
-
-            // 1. Ucieczka przed lisem
-            foxes := [][2]int{}
-            for _, n := range ns {
-                if w.Grid[n[1]][n[0]].Animal == fox {
-                    foxes = append(foxes, n)
-                }
-            }
-            if len(foxes) > 0 && !done {
-                maxDist := -1.0
-                var best [2]int
-                for _, n := range ns {
-                    if w.Grid[n[1]][n[0]].Animal == empty {
-                        minDist := 1000.0
-                        for _, f := range foxes {
-                            dx := float64(n[0] - f[0])
-                            dy := float64(n[1] - f[1])
-                            dist := dx*dx + dy*dy
-                            if dist < minDist {
-                                minDist = dist
-                            }
-                        }
-                        if minDist > maxDist {
-                            maxDist = minDist
-                            best = n
-                        }
-                    }
-                }
-                if maxDist >= 0 {
-                    newGrid[best[1]][best[0]] = cell
-                    newGrid[y][x] = Cell{Ground: w.Grid[y][x].Ground}
-                    cell.Age++
-                    done = true
-                }
-            }
-
-            // 2. Szukanie trawy gdy głodny
-            if !done && cell.Energy < rabbitReproduceEnergy {
-                for _, n := range ns {
-                    ng := w.Grid[n[1]][n[0]]
-                    if ng.Animal == empty && ng.Ground > empty {
-                        // Jeśli bardzo głodny, zjada całą trawę
-                        if cell.Energy < rabbitReproduceEnergy/2 {
-                            cell.Energy += float64(ng.Ground) * 8
-                            cell.Ground = w.Grid[y][x].Ground
-                            newGrid[n[1]][n[0]] = cell
-                            newGrid[y][x] = Cell{Ground: w.Grid[y][x].Ground}
-                            newGrid[n[1]][n[0]].Ground = empty
-                        } else {
-                            cell.Energy += 6
-                            cell.Ground = w.Grid[y][x].Ground
-                            newGrid[n[1]][n[0]] = cell
-                            newGrid[y][x] = Cell{Ground: w.Grid[y][x].Ground}
-                            // Zmniejsz stadium trawy o 1
-                            if ng.Ground == grassTall {
-                                newGrid[n[1]][n[0]].Ground = grassMedium
-                            } else if ng.Ground == grassMedium {
-                                newGrid[n[1]][n[0]].Ground = grassShort
-                            } else {
-                                newGrid[n[1]][n[0]].Ground = empty
-                            }
-                        }
-                        cell.Age++
-                        done = true
-                        break
-                    }
-                }
-            }
-
-            // 3. Szukanie królika do rozmnożenia
-            if !done && cell.Energy >= rabbitReproduceEnergy {
-                for _, n := range ns {
-                    other := w.Grid[n[1]][n[0]]
-                    if other.Animal == rabbit && other.ReproduceCooldown == 0 {
-                        if y < n[1] || (y == n[1] && x < n[0]) {
-                            for _, emptyN := range ns {
-                                if w.Grid[emptyN[1]][emptyN[0]].Animal == empty {
-                                    newGrid[emptyN[1]][emptyN[0]] = Cell{
-                                        Ground:            w.Grid[emptyN[1]][emptyN[0]].Ground,
-                                        Animal:            rabbit,
-                                        Energy:            cell.Energy / 2,
-                                        ReproduceCooldown: rabbitCooldown,
-                                        Age:               0,
-                                    }
-                                    cell.Energy = cell.Energy / 2
-                                    cell.ReproduceCooldown = rabbitCooldown
-                                    newGrid[y][x] = cell
-                                    cell.Age++
-                                    done = true
-                                    break
-                                }
-                            }
-                        }
-                        if done {
-                            break
-                        }
-                    }
-                }
-            }
-
-            // 4. Ruch losowy jeśli nic innego nie zadziałało
-            if !done {
-                rand.Shuffle(len(ns), func(i, j int) { ns[i], ns[j] = ns[j], ns[i] })
-                for _, n := range ns {
-                    if w.Grid[n[1]][n[0]].Animal == empty {
-                        newGrid[n[1]][n[0]] = cell
-                        newGrid[y][x] = Cell{Ground: w.Grid[y][x].Ground}
-                        cell.Age++
-                        break
-                    }
-                }
-            }
-        }
-    }
-    w.Grid = newGrid
-}
+		viewport.Begin()
+		renderer.SetView(viewport.Visible())
+		renderer.Draw(renderState)
+		if followedAlive {
+			cx, cy := renderState.CellCenter(followedPos[0], followedPos[1])
+			rl.DrawCircleLines(int32(cx*float64(cellSize)), int32(cy*float64(cellSize)), float32(cellSize)*0.6, rl.Magenta)
+		}
+		if inspecting {
+			inspector.DrawMarks(renderState, cellSize, inspected[0], inspected[1])
+		}
+		if brush.Active && hovering {
+			brush.DrawCursor(renderState, cellSize, hoverX, hoverY)
+		}
+		viewport.End()
+		viewport.DrawMinimap(renderState)
+
+		currentAnimals := sim.CountAnimals(renderState)
+		rl.DrawText(fmt.Sprintf("Króliki: %d  Lisy: %d  Tura: %d  Ziarno: %d  Topologia: %s",
+			currentAnimals[sim.Rabbit], currentAnimals[sim.Fox], renderState.Turn, renderState.Seed, renderState.Topology), 10, 10, 20, rl.Black)
+
+		control := runner.State()
+		switch {
+		case control.Paused:
+			rl.DrawText("PAUZA (spacja, N – jedna tura)", 10, 40, 20, rl.Red)
+		case control.FastForward:
+			rl.DrawText("PRZEWIJANIE (F)", 10, 40, 20, rl.DarkGreen)
+		default:
+			rl.DrawText(fmt.Sprintf("Tempo: %.3g tur/s (+/-)", control.TPS), 10, 40, 20, rl.DarkGray)
+		}
+		if statusFrames > 0 {
+			rl.DrawText(status, 10, 70, 20, rl.DarkBlue)
+			statusFrames--
+		}
+		if hovering {
+			rl.DrawText(describeCell(renderState, hoverX, hoverY), 10, int32(boardHeight)-30, 20, rl.Black)
+		}
+		if brush.Active {
+			rl.DrawText(brush.Label(), 10, int32(boardHeight)-55, 20, rl.DarkPurple)
+		}
+		if followed != 0 {
+			rl.DrawText(describeAnimal(renderState, followed), 10, 100, 20, rl.Magenta)
+		}
+		if inspecting {
+			inspector.DrawPanel(renderState, inspected[0], inspected[1], rl.GetScreenWidth())
+		}
 
-func (w *World) MoveFoxes() {
-	newGrid := w.Copy().Grid
-
-	for y := 0; y < w.Height; y++ {
-		for x := 0; x < w.Width; x++ {
-			cell := w.Grid[y][x]
-			if cell.Animal == fox && cell.ReproduceCooldown == 0 {
-				ns := neighbors(x, y, w.Width, w.Height)
-				done := false
-
-				// 1. Szukanie królika gdy bardzo głodny
-				if cell.Energy < foxReproduceEnergy/2 && !done {
-					for attempt := 0; attempt < 2; attempt++ {
-						for _, n := range ns {
-							if w.Grid[n[1]][n[0]].Animal == rabbit {
-								cell.Energy += 20 // zwiększ energię po zjedzeniu królika
-								newGrid[n[1]][n[0]] = cell
-								newGrid[y][x] = Cell{Ground: w.Grid[y][x].Ground}
-								continue
-							}
-						}
-					}
-				}
+		chart.Draw(renderState.History, rl.NewRectangle(0, float32(boardHeight), float32(rl.GetScreenWidth()), float32(plotPreviewHeight)))
 
-				// 2. Szukanie królika gdy głodny (standardowo)
-				if !done && cell.Energy < foxReproduceEnergy {
-					for _, n := range ns {
-						if w.Grid[n[1]][n[0]].Animal == rabbit {
-							cell.Energy += 20 // zwiększ energię po zjedzeniu królika
-							newGrid[n[1]][n[0]] = cell
-							newGrid[y][x] = Cell{Ground: w.Grid[y][x].Ground}
-							cell.Age++
-							done = true
-							break
-						}
-					}
-				}
+		rl.EndDrawing()
+	}
 
-				// 3. Szukanie lisa do rozmnożenia
-				if !done && cell.Energy >= foxReproduceEnergy {
-					for _, n := range ns {
-						other := w.Grid[n[1]][n[0]]
-						if other.Animal == fox && other.ReproduceCooldown == 0 {
-							if y < n[1] || (y == n[1] && x < n[0]) {
-								for _, emptyN := range ns {
-									if w.Grid[emptyN[1]][emptyN[0]].Animal == empty {
-										newGrid[emptyN[1]][emptyN[0]] = Cell{
-											Ground:            w.Grid[emptyN[1]][emptyN[0]].Ground,
-											Animal:            fox,
-											Energy:            cell.Energy / 2,
-											ReproduceCooldown: foxCooldown,
-											Age:               0,
-										}
-										cell.Energy = cell.Energy / 2
-										cell.ReproduceCooldown = foxCooldown
-										newGrid[y][x] = cell
-										cell.Age++
-										done = true
-										break
-									}
-								}
-							}
-							if done {
-								break
-							}
-						}
-					}
-				}
+	close(quitChan)
+	<-runDone
+	// Ostatni opublikowany świat; okno mogło nie zdążyć go odebrać
+	for newState := range runner.Updates() {
+		renderState = newState
+	}
 
-				// 4. Ruch losowy jeśli nic innego nie zadziałało
-				if !done {
-					rand.Shuffle(len(ns), func(i, j int) { ns[i], ns[j] = ns[j], ns[i] })
-					for _, n := range ns {
-						if w.Grid[n[1]][n[0]].Animal == empty {
-							newGrid[n[1]][n[0]] = cell
-							newGrid[y][x] = Cell{Ground: w.Grid[y][x].Ground}
-							cell.Age++
-							break
-						}
-					}
-				}
-			}
+	if opts.AnimalsPath != "" {
+		if err := sim.SaveAnimalsCSV(opts.AnimalsPath, renderState); err != nil {
+			fmt.Fprintln(os.Stderr, "błąd zapisu metryk zwierząt:", err)
 		}
 	}
-	w.Grid = newGrid
-}
-
-func (w *World) UpdateEnergy() {
-	for y := 0; y < w.Height; y++ {
-		for x := 0; x < w.Width; x++ {
-			if w.Grid[y][x].Animal == rabbit || w.Grid[y][x].Animal == fox {
-				// Zużycie energii rośnie z wiekiem
-				energyLoss := 1.0 + float64(w.Grid[y][x].Age)/10.0
-				w.Grid[y][x].Energy -= energyLoss
-				if w.Grid[y][x].ReproduceCooldown > 0 {
-					w.Grid[y][x].ReproduceCooldown--
-				}
-				if w.Grid[y][x].Energy <= 0 {
-					w.Grid[y][x].Animal = empty
-					w.Grid[y][x].Energy = 0
-					w.Grid[y][x].ReproduceCooldown = 0
-					w.Grid[y][x].Age = 0
-				}
-			}
+	if opts.PlotPath != "" {
+		if err := SavePlot(opts.PlotPath, renderState.History, 8*vg.Inch, 4*vg.Inch); err != nil {
+			fmt.Fprintln(os.Stderr, "błąd zapisu wykresu:", err)
+		} else {
+			openImage(opts.PlotPath)
 		}
 	}
 }
 
-var popHistory []struct{ Rabbits, Foxes int }
-var paused bool
-
-func (w *World) SimulateWithVisualization(cellSize int, worldHeight int, plotPreviewHeight int) {
-	rl.SetTargetFPS(10)
-	renderState := w.Copy()
-	updateChan := make(chan *World, 1)
-	quitChan := make(chan struct{})
-	popHistory = nil
-
-	go func() {
-		for {
-			select {
-			case <-quitChan:
-				close(updateChan)
-				return
-			default:
-				w.GrowGrass()
-				w.MoveRabbits()
-				w.MoveFoxes()
-				w.UpdateEnergy()
-
-				animals := countAnimals(w)
-				popHistory = append(popHistory, struct{ Rabbits, Foxes int }{
-					Rabbits: animals[rabbit], Foxes: animals[fox],
-				})
-
-				select {
-				case updateChan <- w.Copy():
-				case <-quitChan:
-					close(updateChan)
-					return
-				}
-
-				if animals[rabbit]+animals[fox] == 0 {
-					close(updateChan)
-					return
-				}
-				time.Sleep(100 * time.Millisecond)
-			}
-		}
-	}()
-	var plotTexture rl.Texture2D
-    var plotImageLoaded bool
-    plotUpdateCounter := 0
+// Opis pola pod kursorem: współrzędne, podłoże i zwierzę
+func describeCell(w *sim.World, x, y int) string {
+	cell := w.Grid[y][x]
+	grounds := map[int]string{
+		sim.Empty:       "bez trawy",
+		sim.GrassShort:  "trawa niska",
+		sim.GrassMedium: "trawa średnia",
+		sim.GrassTall:   "trawa wysoka",
+		sim.Obstacle:    "przeszkoda",
+	}
+	text := fmt.Sprintf("Pole (%d, %d): %s", x, y, grounds[cell.Ground])
+	switch cell.Animal {
+	case sim.Rabbit:
+		text += fmt.Sprintf(", królik #%d (energia %.1f, wiek %d)", cell.ID, cell.Energy, cell.Age)
+	case sim.Fox:
+		text += fmt.Sprintf(", lis #%d (energia %.1f, wiek %d)", cell.ID, cell.Energy, cell.Age)
+	}
+	return text
+}
 
-loop:
-    for !rl.WindowShouldClose() {
-        if rl.IsKeyPressed(rl.KeySpace) {
-            paused = !paused
-        }
-
-        if !paused {
-            select {
-            case newState, ok := <-updateChan:
-                if ok {
-                    renderState = newState
-                } else {
-                    break loop
-                }
-            default:
-            }
-        }
-
-        rl.BeginDrawing()
-        rl.ClearBackground(rl.RayWhite)
-        renderState.DrawWorld(cellSize)
-
-        currentAnimals := countAnimals(renderState)
-        rl.DrawText(fmt.Sprintf("Króliki: %d  Lisy: %d",
-            currentAnimals[rabbit], currentAnimals[fox]), 10, 10, 20, rl.Black)
-
-        if paused {
-            rl.DrawText("PAUZA (spacja)", 10, 40, 20, rl.Red)
-        }
-
-        plotUpdateCounter++
-        if plotUpdateCounter >= 10 {
-            ShowPlot()
-            if plotImageLoaded {
-                rl.UnloadTexture(plotTexture)
-            }
-            img := rl.LoadImage("populacje_preview.png")
-			plotTexture = rl.LoadTextureFromImage(img)
-			rl.UnloadImage(img)
-            plotImageLoaded = true
-            plotUpdateCounter = 0
-        }
-
-        if plotImageLoaded {
-			plotW := int32(plotTexture.Width)
-			plotH := int32(plotTexture.Height)
-			winW := rl.GetScreenWidth()
-			rl.DrawTexturePro(
-				plotTexture,
-				rl.NewRectangle(0, 0, float32(plotW), float32(plotH)),
-				rl.NewRectangle(0, float32(cellSize*worldHeight), float32(winW), float32(plotPreviewHeight)),
-				rl.NewVector2(0, 0),
-				0,
-				rl.White,
-			)
-		}
-
-        rl.EndDrawing()
-    }
-
-    if plotImageLoaded {
-        rl.UnloadTexture(plotTexture)
-    }
-
-    close(quitChan)
-    for range updateChan {
-    }
-
-    ShowPlot()
-    openImage("populacje.png")
+// Opis śledzonego zwierzęcia: pochodzenie, liczba młodych i ewentualna śmierć
+func describeAnimal(w *sim.World, id uint64) string {
+	r, ok := w.Animal(id)
+	if !ok {
+		return ""
+	}
+	name := "Królik"
+	if r.Species == sim.Fox {
+		name = "Lis"
+	}
+	text := fmt.Sprintf("%s #%d, ur. w turze %d", name, r.ID, r.BirthTurn)
+	if r.Parents[0] != 0 {
+		text += fmt.Sprintf(" (rodzice #%d i #%d)", r.Parents[0], r.Parents[1])
+	}
+	text += fmt.Sprintf(", młode: %d", len(w.Children(id)))
+	switch r.Cause {
+	case sim.CauseStarvation:
+		text += fmt.Sprintf(", zmarł z głodu w turze %d", r.DeathTurn)
+	case sim.CausePredation:
+		text += fmt.Sprintf(", zjedzony w turze %d przez #%d", r.DeathTurn, r.KilledBy)
+	case sim.CauseOldAge:
+		text += fmt.Sprintf(", zmarł ze starości w turze %d", r.DeathTurn)
+	case sim.CauseRemoved:
+		text += fmt.Sprintf(", usunięty z planszy w turze %d", r.DeathTurn)
+	}
+	return text
 }
 
-func countAnimals(w *World) map[int]int {
-	counts := make(map[int]int)
-	for y := 0; y < w.Height; y++ {
-		for x := 0; x < w.Width; x++ {
-			if w.Grid[y][x].Animal != empty {
-				counts[w.Grid[y][x].Animal]++
-			}
-		}
+// Wczytuje zrzut i podmienia nim świat w runnerze; nowy stan do wyświetlenia
+// przychodzi z runner.Updates
+func loadForVisualization(path string, current *sim.World, runner *sim.Runner) (*sim.World, error) {
+	loaded, err := sim.LoadSnapshot(path)
+	if err != nil {
+		return nil, err
 	}
-	return counts
+	// Okno ma stały rozmiar, więc plansza musi mieć te same wymiary i rodzaj siatki
+	if loaded.Width != current.Width || loaded.Height != current.Height {
+		return nil, fmt.Errorf("zrzut ma planszę %dx%d, a okno %dx%d", loaded.Width, loaded.Height, current.Width, current.Height)
+	}
+	if (loaded.Neighborhood == sim.NeighborhoodHex) != (current.Neighborhood == sim.NeighborhoodHex) {
+		return nil, errors.New("zrzut ma inny rodzaj siatki (kwadratowa/sześciokątna) niż okno")
+	}
+	// Po podmianie świat należy już do gorutyny symulacji
+	shown := loaded.Copy()
+	if !runner.Load(loaded) {
+		return nil, errors.New("symulacja już się zakończyła")
+	}
+	return shown, nil
 }
 
-func ShowPlot() {
+// Zapisuje wykres historii populacji do pliku o podanych wymiarach
+func SavePlot(path string, history []sim.Population, width, height vg.Length) error {
 	p := plot.New()
 	p.Title.Text = "Populacje w czasie"
 	p.X.Label.Text = "Tura"
 	p.Y.Label.Text = "Liczebność"
 
-	rabbits := make(plotter.XYs, len(popHistory))
-	foxes := make(plotter.XYs, len(popHistory))
-	for i, v := range popHistory {
-		rabbits[i].X = float64(i)
+	rabbits := make(plotter.XYs, len(history))
+	foxes := make(plotter.XYs, len(history))
+	grass := make(plotter.XYs, len(history))
+	for i, v := range history {
+		rabbits[i].X = float64(v.Turn)
 		rabbits[i].Y = float64(v.Rabbits)
-		foxes[i].X = float64(i)
+		foxes[i].X = float64(v.Turn)
 		foxes[i].Y = float64(v.Foxes)
+		grass[i].X = float64(v.Turn)
+		grass[i].Y = float64(v.GrassShort + v.GrassMedium + v.GrassTall)
 	}
 	l1, _ := plotter.NewLine(rabbits)
 	l2, _ := plotter.NewLine(foxes)
+	l3, _ := plotter.NewLine(grass)
 	l1.Color = plotter.DefaultLineStyle.Color
 	l2.Color = plotter.DefaultLineStyle.Color
 	l2.LineStyle.Dashes = []vg.Length{vg.Points(5), vg.Points(5)}
-	p.Add(l1, l2)
+	l3.Color = color.RGBA{R: 70, G: 160, B: 60, A: 255}
+	l3.LineStyle.Dashes = []vg.Length{vg.Points(1), vg.Points(3)}
+	p.Add(l1, l2, l3)
 	p.Legend.Add("Króliki", l1)
 	p.Legend.Add("Lisy", l2)
+	p.Legend.Add("Trawa (pola)", l3)
 	p.Legend.Top = true
 
-	p.Save(8*vg.Inch, 4*vg.Inch, "populacje.png")
-	p.Save(8*vg.Inch, 1.5*vg.Inch, "populacje_preview.png")
+	return p.Save(width, height, path)
 }
 
 func openImage(filename string) {
@@ -719,18 +514,153 @@ func openImage(filename string) {
 	}
 }
 
+// Wczytuje parametry z pliku konfiguracyjnego, zachowując wartości flag podanych
+// jawnie w wierszu poleceń
+func applyConfig(params *sim.Params, path string) error {
+	explicit := map[string]string{}
+	flag.Visit(func(f *flag.Flag) { explicit[f.Name] = f.Value.String() })
+
+	loaded, err := sim.LoadParams(path)
+	if err != nil {
+		return err
+	}
+	*params = loaded
+	// Flagi wskazują na pola params, więc ponowne ustawienie nadpisuje wartości z pliku
+	for name, value := range explicit {
+		if err := flag.Set(name, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func main() {
-	params := ShowMenu()
+	params := sim.DefaultParams()
+	configPath := flag.String("config", "", "plik JSON z parametrami symulacji (flagi podane jawnie mają pierwszeństwo)")
+	flag.IntVar(&params.Width, "width", params.Width, "szerokość planszy")
+	flag.IntVar(&params.Height, "height", params.Height, "wysokość planszy")
+	flag.IntVar(&params.Rabbits, "rabbits", params.Rabbits, "początkowa liczba królików")
+	flag.IntVar(&params.Foxes, "foxes", params.Foxes, "początkowa liczba lisów")
+	flag.Float64Var(&params.GrowthRate, "growth", params.GrowthRate, "tempo wzrostu trawy")
+	flag.TextVar(&params.Conflict, "conflict", params.Conflict, "rozstrzyganie konfliktów ruchu: random, energy lub stay")
+	flag.TextVar(&params.Topology, "topology", params.Topology, "krawędzie planszy: bounded, torus lub reflective")
+	flag.TextVar(&params.Neighborhood, "neighborhood", params.Neighborhood, "kształt sąsiedztwa i pola widzenia: moore, vonneumann lub hex (siatka sześciokątna)")
+	flag.IntVar(&params.Rabbit.Vision, "rabbit-vision", params.Rabbit.Vision, "zasięg wzroku królików w polach (1 = tylko sąsiednie)")
+	flag.IntVar(&params.Rabbit.MaxAge, "rabbit-max-age", params.Rabbit.MaxAge, "wiek, w którym królik umiera ze starości (0 = bez limitu)")
+	flag.IntVar(&params.Fox.MaxAge, "fox-max-age", params.Fox.MaxAge, "wiek, w którym lis umiera ze starości (0 = bez limitu)")
+	flag.IntVar(&params.Fox.Vision, "fox-vision", params.Fox.Vision, "zasięg wzroku lisów w polach (1 = tylko sąsiednie)")
+	flag.BoolVar(&params.Rabbit.Pathfinding, "rabbit-pathfinding", params.Rabbit.Pathfinding, "króliki szukają drogi do trawy i partnera oraz uciekają po drodze (w zasięgu wzroku)")
+	flag.BoolVar(&params.Fox.Pathfinding, "fox-pathfinding", params.Fox.Pathfinding, "lisy szukają drogi do królików i partnera (w zasięgu wzroku)")
+	flag.Float64Var(&params.Obstacles, "obstacles", params.Obstacles, "odsetek pól z przeszkodami (0–0.9)")
+	headless := flag.Bool("headless", false, "uruchom symulację bez okna")
+	terminal := flag.Bool("tui", false, "wyświetlaj symulację w terminalu (kolory ANSI), np. przez SSH")
+	serveAddr := flag.String("serve", "", "adres (np. localhost:8080), pod którym serwer HTTP pokazuje symulację w przeglądarce")
+	rendererMode := flag.String("renderer", "auto", "rysowanie planszy w oknie: textures, pixels lub auto (piksele od 150x150 pól); R przełącza w trakcie")
+	flag.Uint64Var(&params.Seed, "seed", params.Seed, "ziarno generatora liczb losowych (0 = losowe)")
+	opts := RunOptions{}
+	flag.IntVar(&opts.MaxTurns, "turns", 1000, "maksymalna liczba tur w trybie bez okna")
+	flag.Float64Var(&opts.TPS, "tps", 10, "początkowe tempo symulacji w oknie i terminalu (tury na sekundę)")
+	flag.StringVar(&opts.HistoryPath, "csv", "", "plik CSV ze statystykami kolejnych tur")
+	flag.StringVar(&opts.JSONLPath, "jsonl", "", "plik JSON Lines ze statystykami kolejnych tur")
+	flag.StringVar(&opts.PlotPath, "plot", "populacje.png", "plik z wykresem populacji")
+	flag.StringVar(&opts.ParamsPath, "params-out", "", "plik z zapisanymi parametrami i ziarnem przebiegu")
+	flag.StringVar(&opts.LoadPath, "load", "", "rozpocznij od zapisanego zrzutu świata")
+	flag.StringVar(&opts.EventsPath, "events", "", "plik JSON Lines ze zdarzeniami: narodziny, śmierć (z przyczyną), jedzenie, ruch")
+	flag.StringVar(&opts.AnimalsPath, "animals", "", "plik CSV z metrykami wszystkich zwierząt (pochodzenie, narodziny, śmierć)")
+	flag.StringVar(&opts.SnapshotPath, "snapshot", "", "plik zrzutu: F5/F9 w oknie, stan końcowy w trybie bez okna (.json = JSON, inne = binarny)")
+	flag.StringVar(&opts.SnapshotDir, "snapshot-dir", "zrzuty", "katalog, w którym serwer (-serve) zapisuje zrzuty z POST /api/snapshot (pusty = zapis wyłączony)")
+	sweepPath := flag.String("sweep", "", "plik JSON z przeglądem parametrów; uruchamia serię przebiegów bez okna")
+	sweepOut := flag.String("sweep-out", "przeglad.csv", "plik CSV z podsumowaniem przeglądu parametrów")
+	workers := flag.Int("workers", 0, "liczba równoległych przebiegów przeglądu (0 = liczba rdzeni)")
+	flag.Parse()
+
+	// Przegląd ma własne parametry bazowe w pliku, więc pozostałe flagi są pomijane
+	if *sweepPath != "" {
+		if err := RunSweep(*sweepPath, *sweepOut, *workers); err != nil {
+			fmt.Fprintln(os.Stderr, "błąd:", err)
+			os.Exit(1)
+		}
+		return
+	}
 
-	world := NewWorld(params.Width, params.Height, 8, params.GrowthRate)
-	world.Initialize(params.Rabbits, params.Foxes)
+	if *configPath != "" {
+		if err := applyConfig(&params, *configPath); err != nil {
+			fmt.Fprintln(os.Stderr, "błąd:", err)
+			os.Exit(1)
+		}
+	}
+	if err := params.Validate(); err != nil {
+		fmt.Fprintln(os.Stderr, "nieprawidłowe parametry:")
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if params.Seed == 0 {
+		params.Seed = randomSeed()
+	}
+	switch *rendererMode {
+	case "auto", "textures", "pixels":
+	default:
+		fmt.Fprintf(os.Stderr, "błąd: nieznany tryb rysowania %q (dostępne: auto, textures, pixels)\n", *rendererMode)
+		os.Exit(1)
+	}
+
+	if *serveAddr != "" {
+		if err := RunServer(params, opts, *serveAddr); err != nil {
+			fmt.Fprintln(os.Stderr, "błąd:", err)
+			os.Exit(1)
+		}
+		return
+	}
+	if *terminal {
+		if err := RunTUI(params, opts); err != nil {
+			fmt.Fprintln(os.Stderr, "błąd:", err)
+			os.Exit(1)
+		}
+		return
+	}
+	if *headless {
+		if err := RunHeadless(params, opts); err != nil {
+			fmt.Fprintln(os.Stderr, "błąd:", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	// Przy wczytywaniu zrzutu parametry pochodzą z pliku, więc menu jest pomijane
+	if opts.LoadPath == "" {
+		params = ShowMenu(params)
+		// Zamknięcie okna menu zwraca parametry bez sprawdzenia przy starcie
+		if err := params.Validate(); err != nil {
+			fmt.Fprintln(os.Stderr, "nieprawidłowe parametry:")
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	}
+
+	world, params, err := createWorld(params, opts.LoadPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "błąd:", err)
+		os.Exit(1)
+	}
 
 	cellSize := 32
-	plotPreviewHeight := int(float32(params.Width*cellSize) * 1.5 / 8.0)
-	rl.InitWindow(int32(params.Width*cellSize), int32(params.Height*cellSize+plotPreviewHeight), "Symulacja Ekosystemu")
-	loadTextures()
-	defer unloadTextures()
+	boardWidth, boardHeight := boardPixels(world, cellSize)
+	// Większe plansze ogląda się przez kamerę (przybliżanie, przesuwanie, minimapa)
+	boardWidth, boardHeight = min(boardWidth, maxBoardViewWidth), min(boardHeight, maxBoardViewHeight)
+	plotPreviewHeight := int(float32(boardWidth) * 1.5 / 8.0)
+	rl.InitWindow(int32(boardWidth), int32(boardHeight+plotPreviewHeight), "Symulacja Ekosystemu")
 	defer rl.CloseWindow()
+	renderer := &SwitchRenderer{
+		Textures: NewTextureRenderer(cellSize),
+		Pixels:   NewPixelRenderer(cellSize),
+		UsePixel: *rendererMode == "pixels" || *rendererMode == "auto" && world.Width*world.Height >= pixelModeCells,
+	}
+	defer renderer.Unload()
 
-	world.SimulateWithVisualization(32, params.Height, plotPreviewHeight)
+	SimulateWithVisualization(world, renderer, cellSize, boardWidth, boardHeight, plotPreviewHeight, opts)
+	if opts.ParamsPath != "" {
+		if err := sim.SaveParams(opts.ParamsPath, params); err != nil {
+			fmt.Fprintln(os.Stderr, "błąd:", err)
+		}
+	}
 }
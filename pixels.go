@@ -0,0 +1,129 @@
+package main
+
+import (
+	"image/color"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+
+	"example.com/mod/sim"
+)
+
+// PixelRenderer rysuje planszę jako jedną teksturę, w której każde pole to
+// piksel w kolorze zwierzęcia albo podłoża. Tekstura jest aktualizowana raz
+// na nowy stan świata i rysowana jednym wywołaniem, więc nadaje się do
+// dużych plansz (np. 500x500), przy których rysowanie tekstur pól jest za wolne.
+// Na siatce sześciokątnej pole zajmuje dwa piksele, a co drugi wiersz jest
+// przesunięty o jeden piksel, tak jak wiersze sześciokątów.
+type PixelRenderer struct {
+	CellSize int
+
+	tex    rl.Texture2D
+	pixels []color.RGBA
+	world  *sim.World // stan, z którego pochodzi zawartość tekstury
+}
+
+var _ BoardRenderer = (*PixelRenderer)(nil)
+
+func NewPixelRenderer(cellSize int) *PixelRenderer {
+	return &PixelRenderer{CellSize: cellSize}
+}
+
+func (r *PixelRenderer) Unload() {
+	if r.tex.ID != 0 {
+		rl.UnloadTexture(r.tex)
+	}
+}
+
+// Cała tekstura jest rysowana jednym wywołaniem, więc widok nie ma znaczenia
+func (r *PixelRenderer) SetView(view rl.Rectangle) {}
+
+func (r *PixelRenderer) Draw(w *sim.World) {
+	hex := w.Neighborhood == sim.NeighborhoodHex
+	texW, texH := w.Width, w.Height
+	if hex {
+		texW = 2*w.Width + 1
+	}
+	if r.tex.ID == 0 || int(r.tex.Width) != texW || int(r.tex.Height) != texH {
+		r.Unload()
+		img := rl.GenImageColor(texW, texH, groundColors[sim.Empty])
+		r.tex = rl.LoadTextureFromImage(img)
+		rl.UnloadImage(img)
+		rl.SetTextureFilter(r.tex, rl.FilterPoint)
+		r.pixels = make([]color.RGBA, texW*texH)
+		r.world = nil
+	}
+
+	if r.world != w {
+		r.world = w
+		for y := 0; y < w.Height; y++ {
+			row := r.pixels[y*texW : (y+1)*texW]
+			if hex {
+				// Wolny piksel na początku lub końcu wiersza w kolorze tła
+				row[0], row[texW-1] = rl.RayWhite, rl.RayWhite
+			}
+			for x := 0; x < w.Width; x++ {
+				c := cellColor(w.Grid[y][x])
+				if hex {
+					i := 2*x + y&1
+					row[i], row[i+1] = c, c
+				} else {
+					row[x] = c
+				}
+			}
+		}
+		rl.UpdateTexture(r.tex, r.pixels)
+	}
+
+	bw, bh := boardPixels(w, r.CellSize)
+	src := rl.NewRectangle(0, 0, float32(texW), float32(texH))
+	rl.DrawTexturePro(r.tex, src, rl.NewRectangle(0, 0, float32(bw), float32(bh)), rl.Vector2{}, 0, rl.White)
+	drawEdges(w, r.CellSize)
+}
+
+// Kolor pola: zwierzę, a gdy go nie ma – podłoże
+func cellColor(c sim.Cell) color.RGBA {
+	switch c.Animal {
+	case sim.Rabbit:
+		return rabbitColor
+	case sim.Fox:
+		return foxColor
+	}
+	return groundColors[c.Ground]
+}
+
+// Liczba pól, od której okno zaczyna w trybie pikseli (tryb auto)
+const pixelModeCells = 150 * 150
+
+// SwitchRenderer przełącza w trakcie działania między teksturami pól
+// a trybem pikseli (klawisz R)
+type SwitchRenderer struct {
+	Textures *TextureRenderer
+	Pixels   *PixelRenderer
+	UsePixel bool
+}
+
+var _ BoardRenderer = (*SwitchRenderer)(nil)
+
+func (r *SwitchRenderer) current() BoardRenderer {
+	if r.UsePixel {
+		return r.Pixels
+	}
+	return r.Textures
+}
+
+func (r *SwitchRenderer) Draw(w *sim.World) {
+	r.current().Draw(w)
+}
+
+func (r *SwitchRenderer) SetView(view rl.Rectangle) {
+	r.current().SetView(view)
+}
+
+func (r *SwitchRenderer) Toggle() {
+	r.UsePixel = !r.UsePixel
+}
+
+func (r *SwitchRenderer) Unload() {
+	r.Textures.Unload()
+	r.Pixels.Unload()
+}
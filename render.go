@@ -0,0 +1,181 @@
+package main
+
+import (
+	"math"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+
+	"example.com/mod/sim"
+)
+
+// Renderer planszy w oknie, który rysuje tylko pola z widocznego fragmentu
+type BoardRenderer interface {
+	sim.Renderer
+	SetView(view rl.Rectangle) // widoczny fragment w pikselach planszy; pusty = cała plansza
+}
+
+// TextureRenderer rysuje świat w oknie raylib przy pomocy tekstur PNG
+type TextureRenderer struct {
+	CellSize int
+	View     rl.Rectangle // widoczny fragment planszy; pola poza nim są pomijane
+
+	texEmpty      rl.Texture2D
+	texGrassShort rl.Texture2D
+	texGrassMed   rl.Texture2D
+	texGrassTall  rl.Texture2D
+	texRabbit     rl.Texture2D
+	texFox        rl.Texture2D
+}
+
+var _ BoardRenderer = (*TextureRenderer)(nil)
+
+// Wczytuje tekstury; wymaga otwartego okna raylib
+func NewTextureRenderer(cellSize int) *TextureRenderer {
+	return &TextureRenderer{
+		CellSize:      cellSize,
+		texEmpty:      rl.LoadTexture("empty.png"),
+		texGrassShort: rl.LoadTexture("grass_short.png"),
+		texGrassMed:   rl.LoadTexture("grass_medium.png"),
+		texGrassTall:  rl.LoadTexture("grass_tall.png"),
+		texRabbit:     rl.LoadTexture("rabbit.png"),
+		texFox:        rl.LoadTexture("fox.png"),
+	}
+}
+
+func (r *TextureRenderer) Unload() {
+	rl.UnloadTexture(r.texEmpty)
+	rl.UnloadTexture(r.texGrassShort)
+	rl.UnloadTexture(r.texGrassMed)
+	rl.UnloadTexture(r.texGrassTall)
+	rl.UnloadTexture(r.texRabbit)
+	rl.UnloadTexture(r.texFox)
+}
+
+func (r *TextureRenderer) SetView(view rl.Rectangle) {
+	r.View = view
+}
+
+func (r *TextureRenderer) Draw(w *sim.World) {
+	if w.Neighborhood == sim.NeighborhoodHex {
+		r.drawHex(w)
+		drawEdges(w, r.CellSize)
+		return
+	}
+	cellSize := r.CellSize
+	xmin, ymin, xmax, ymax := visibleCells(w, r.View, cellSize)
+	for y := ymin; y < ymax; y++ {
+		for x := xmin; x < xmax; x++ {
+			pos := rl.NewVector2(float32(x*cellSize), float32(y*cellSize))
+			rl.DrawTextureEx(r.texEmpty, pos, 0, float32(cellSize)/float32(r.texEmpty.Width), rl.White)
+			switch w.Grid[y][x].Ground {
+			case sim.GrassShort:
+				rl.DrawTextureEx(r.texGrassShort, pos, 0, float32(cellSize)/float32(r.texGrassShort.Width), rl.White)
+			case sim.GrassMedium:
+				rl.DrawTextureEx(r.texGrassMed, pos, 0, float32(cellSize)/float32(r.texGrassMed.Width), rl.White)
+			case sim.GrassTall:
+				rl.DrawTextureEx(r.texGrassTall, pos, 0, float32(cellSize)/float32(r.texGrassTall.Width), rl.White)
+			case sim.Obstacle:
+				rl.DrawRectangle(int32(x*cellSize), int32(y*cellSize), int32(cellSize), int32(cellSize), obstacleColor)
+			}
+			if w.Grid[y][x].Animal == sim.Rabbit {
+				rl.DrawTextureEx(r.texRabbit, pos, 0, float32(cellSize)/float32(r.texRabbit.Width), rl.White)
+			} else if w.Grid[y][x].Animal == sim.Fox {
+				rl.DrawTextureEx(r.texFox, pos, 0, float32(cellSize)/float32(r.texFox.Width), rl.White)
+			}
+		}
+	}
+	drawEdges(w, cellSize)
+}
+
+// Kolor przeszkód (nie mają tekstury)
+var obstacleColor = rl.NewColor(70, 70, 75, 255)
+
+// Kolory podłoża tam, gdzie nie ma tekstur: na siatce sześciokątnej (tekstury
+// są kwadratowe), na minimapie i w trybie pikseli
+var groundColors = map[int]rl.Color{
+	sim.Obstacle:    obstacleColor,
+	sim.Empty:       rl.NewColor(222, 204, 160, 255),
+	sim.GrassShort:  rl.NewColor(170, 214, 110, 255),
+	sim.GrassMedium: rl.NewColor(110, 180, 70, 255),
+	sim.GrassTall:   rl.NewColor(60, 130, 40, 255),
+}
+
+// Kolory zwierząt na minimapie i w trybie pikseli
+var (
+	rabbitColor = rl.NewColor(240, 240, 240, 255)
+	foxColor    = rl.NewColor(220, 110, 30, 255)
+)
+
+// Rysuje siatkę sześciokątną: pola jako wielokąty w kolorze podłoża,
+// zwierzęta jako tekstury wpisane w sześciokąt
+func (r *TextureRenderer) drawHex(w *sim.World) {
+	size := float32(r.CellSize)
+	radius := size / float32(math.Sqrt(3))
+	animalSize := size * 0.8
+	xmin, ymin, xmax, ymax := visibleCells(w, r.View, r.CellSize)
+	for y := ymin; y < ymax; y++ {
+		for x := xmin; x < xmax; x++ {
+			cx, cy := w.CellCenter(x, y)
+			center := rl.NewVector2(float32(cx)*size, float32(cy)*size)
+			// Obrót o 30° stawia sześciokąt na wierzchołku
+			rl.DrawPoly(center, 6, radius, 30, groundColors[w.Grid[y][x].Ground])
+			rl.DrawPolyLines(center, 6, radius, 30, rl.Fade(rl.DarkGray, 0.4))
+
+			var tex rl.Texture2D
+			switch w.Grid[y][x].Animal {
+			case sim.Rabbit:
+				tex = r.texRabbit
+			case sim.Fox:
+				tex = r.texFox
+			default:
+				continue
+			}
+			pos := rl.NewVector2(center.X-animalSize/2, center.Y-animalSize/2)
+			rl.DrawTextureEx(tex, pos, 0, animalSize/float32(tex.Width), rl.White)
+		}
+	}
+}
+
+// Rozmiar planszy w pikselach przy danym rozmiarze pola
+func boardPixels(w *sim.World, cellSize int) (int, int) {
+	bw, bh := w.BoardSize()
+	return int(math.Ceil(bw * float64(cellSize))), int(math.Ceil(bh * float64(cellSize)))
+}
+
+// Zakres pól przecinających widok (w pikselach planszy); pusty widok oznacza całą planszę
+func visibleCells(w *sim.World, view rl.Rectangle, cellSize int) (xmin, ymin, xmax, ymax int) {
+	if view.Width <= 0 || view.Height <= 0 {
+		return 0, 0, w.Width, w.Height
+	}
+	size := float64(cellSize)
+	return w.CellsIn(float64(view.X)/size, float64(view.Y)/size, float64(view.X+view.Width)/size, float64(view.Y+view.Height)/size)
+}
+
+// Pole planszy pod punktem (w pikselach planszy); false, gdy punkt jest poza planszą
+func pickCell(w *sim.World, cellSize int, p rl.Vector2) (x, y int, ok bool) {
+	return w.CellAt(float64(p.X)/float64(cellSize), float64(p.Y)/float64(cellSize))
+}
+
+// Oznacza krawędzie planszy zgodnie z topologią: na torusie przerywana
+// niebieska linia (krawędź przechodzi na przeciwną stronę), przy odbijaniu
+// gruba pomarańczowa ramka, na planszy ograniczonej cienka ciemna ramka
+func drawEdges(w *sim.World, cellSize int) {
+	bw, bh := boardPixels(w, cellSize)
+	width, height := int32(bw), int32(bh)
+	switch w.Topology {
+	case sim.TopologyTorus:
+		dash := int32(cellSize / 4)
+		for x := int32(0); x < width; x += 2 * dash {
+			rl.DrawRectangle(x, 0, dash, 3, rl.SkyBlue)
+			rl.DrawRectangle(x, height-3, dash, 3, rl.SkyBlue)
+		}
+		for y := int32(0); y < height; y += 2 * dash {
+			rl.DrawRectangle(0, y, 3, dash, rl.SkyBlue)
+			rl.DrawRectangle(width-3, y, 3, dash, rl.SkyBlue)
+		}
+	case sim.TopologyReflective:
+		rl.DrawRectangleLinesEx(rl.NewRectangle(0, 0, float32(width), float32(height)), 4, rl.Orange)
+	default:
+		rl.DrawRectangleLinesEx(rl.NewRectangle(0, 0, float32(width), float32(height)), 2, rl.DarkGray)
+	}
+}
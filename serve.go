@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+
+	"example.com/mod/sim"
+	"example.com/mod/web"
+)
+
+// Uruchamia symulację z podglądem w przeglądarce pod adresem addr. Serwer
+// działa do Ctrl+C (także po wyginięciu zwierząt, pokazując stan końcowy),
+// po czym wyniki zapisywane są jak w trybie bez okna.
+func RunServer(params sim.Params, opts RunOptions, addr string) error {
+	world, params, err := createWorld(params, opts.LoadPath)
+	if err != nil {
+		return err
+	}
+
+	history, err := openHistoryOutput(opts)
+	if err != nil {
+		return err
+	}
+	defer history.Close()
+	history.Attach(world)
+	if err := history.Write(world.History...); err != nil {
+		return err
+	}
+
+	runner := sim.NewRunner(world, opts.TPS)
+	var writeErr error
+	runner.OnStep = func(w *sim.World, pop sim.Population) {
+		if err := history.Write(pop); err != nil && writeErr == nil {
+			writeErr = err
+		}
+	}
+	runner.OnLoad = history.Attach
+	server := web.NewServer(runner)
+	server.SnapshotDir = opts.SnapshotDir
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+	simDone := make(chan struct{})
+	go func() {
+		defer close(simDone)
+		server.Run(ctx.Done())
+	}()
+
+	httpServer := &http.Server{Addr: addr, Handler: server.Handler()}
+	serveErr := make(chan error, 1)
+	go func() { serveErr <- httpServer.ListenAndServe() }()
+	fmt.Printf("Podgląd symulacji: http://%s/ (Ctrl+C kończy)\n", displayAddr(addr))
+
+	select {
+	case <-ctx.Done():
+	case err := <-serveErr:
+		cancel()
+		<-simDone
+		return fmt.Errorf("serwer HTTP: %w", err)
+	}
+	<-simDone
+	// Strumienie zdarzeń nie kończą się same, więc serwer jest zamykany bez czekania na nie
+	if err := httpServer.Close(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("serwer HTTP: %w", err)
+	}
+
+	if writeErr != nil {
+		return writeErr
+	}
+	if err := history.Close(); err != nil {
+		return err
+	}
+	return saveResults(server.World(), params, opts)
+}
+
+// Adres do wyświetlenia w przeglądarce: ":8080" oznacza localhost:8080
+func displayAddr(addr string) string {
+	if len(addr) > 0 && addr[0] == ':' {
+		return "localhost" + addr
+	}
+	return addr
+}
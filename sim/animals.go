@@ -0,0 +1,186 @@
+package sim
+
+import (
+	"fmt"
+	"os"
+)
+
+// DeathCause to przyczyna śmierci zwierzęcia
+type DeathCause int
+
+const (
+	CauseNone       DeathCause = iota // zwierzę żyje
+	CauseStarvation                   // śmierć z głodu (energia spadła do zera)
+	CausePredation                    // zjedzone przez drapieżnika
+	CauseOldAge                       // śmierć ze starości (wiek osiągnął MaxAge gatunku)
+	CauseRemoved                      // usunięte z planszy ręcznie (pędzel, API)
+)
+
+var deathCauseNames = []string{"none", "starvation", "predation", "old_age", "removed"}
+
+func (c DeathCause) String() string {
+	if c < 0 || int(c) >= len(deathCauseNames) {
+		return fmt.Sprintf("DeathCause(%d)", int(c))
+	}
+	return deathCauseNames[c]
+}
+
+func (c DeathCause) MarshalText() ([]byte, error) {
+	if c < 0 || int(c) >= len(deathCauseNames) {
+		return nil, fmt.Errorf("nieznana przyczyna śmierci %d", int(c))
+	}
+	return []byte(c.String()), nil
+}
+
+func (c *DeathCause) UnmarshalText(text []byte) error {
+	for i, n := range deathCauseNames {
+		if n == string(text) {
+			*c = DeathCause(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("nieznana przyczyna śmierci %q", text)
+}
+
+// AnimalRecord to metryka jednego zwierzęcia: kiedy i od kogo się urodziło
+// oraz kiedy i dlaczego zginęło. Numery tur są takie jak w Population.Turn.
+type AnimalRecord struct {
+	ID        uint64     `json:"id"`
+	Species   int        `json:"species"`            // Rabbit lub Fox
+	BirthTurn int        `json:"birthTurn"`          // 0 dla zwierząt rozmieszczonych na starcie
+	Parents   [2]uint64  `json:"parents"`            // rodzic, który urodził młode, i jego partner; 0 = brak
+	DeathTurn int        `json:"deathTurn"`          // 0 = żyje
+	Cause     DeathCause `json:"cause"`              // CauseNone, dopóki zwierzę żyje
+	KilledBy  uint64     `json:"killedBy,omitempty"` // drapieżnik, który zjadł zwierzę
+}
+
+func (r AnimalRecord) Alive() bool {
+	return r.Cause == CauseNone
+}
+
+// Długość życia w turach; dla żyjących – do tury turn
+func (r AnimalRecord) Lifespan(turn int) int {
+	if r.Alive() {
+		return turn - r.BirthTurn
+	}
+	return r.DeathTurn - r.BirthTurn
+}
+
+// Nadaje nowemu zwierzęciu identyfikator i zakłada mu metrykę
+func (w *World) register(species, birthTurn int, parents [2]uint64) uint64 {
+	id := uint64(len(w.animals)) + 1
+	w.animals = append(w.animals, AnimalRecord{ID: id, Species: species, BirthTurn: birthTurn, Parents: parents})
+	return id
+}
+
+// Zapisuje śmierć zwierzęcia w bieżącej turze
+func (w *World) recordDeath(id uint64, cause DeathCause, killer uint64) {
+	if id == 0 || id > uint64(len(w.animals)) {
+		return
+	}
+	r := &w.animals[id-1]
+	r.DeathTurn = w.Turn + 1
+	r.Cause = cause
+	r.KilledBy = killer
+}
+
+// Zakłada metryki zwierzętom bez identyfikatora (po Initialize), w kolejności
+// wierszy planszy; datę urodzenia wylicza z wieku
+func (w *World) registerUnnamed() {
+	for y := 0; y < w.Height; y++ {
+		for x := 0; x < w.Width; x++ {
+			c := &w.Grid[y][x]
+			if c.Animal != Empty && c.ID == 0 {
+				c.ID = w.register(c.Animal, max(0, w.Turn-c.Age), [2]uint64{})
+			}
+		}
+	}
+}
+
+// Metryka zwierzęcia o podanym identyfikatorze
+func (w *World) Animal(id uint64) (AnimalRecord, bool) {
+	if id == 0 || id > uint64(len(w.animals)) {
+		return AnimalRecord{}, false
+	}
+	return w.animals[id-1], true
+}
+
+// Metryki wszystkich zwierząt, jakie kiedykolwiek żyły, w kolejności identyfikatorów
+func (w *World) Animals() []AnimalRecord {
+	return append([]AnimalRecord(nil), w.animals...)
+}
+
+// Młode zwierzęcia (jako rodzica lub partnera)
+func (w *World) Children(id uint64) []AnimalRecord {
+	var result []AnimalRecord
+	// Młode mają większe identyfikatory niż rodzice
+	for _, r := range w.animals[min(id, uint64(len(w.animals))):] {
+		if r.Parents[0] == id || r.Parents[1] == id {
+			result = append(result, r)
+		}
+	}
+	return result
+}
+
+// Przodkowie zwierzęcia (rodzice, dziadkowie, ...), każdy raz, od najbliższych
+func (w *World) Ancestors(id uint64) []AnimalRecord {
+	var result []AnimalRecord
+	seen := map[uint64]bool{}
+	queue := []uint64{id}
+	for len(queue) > 0 {
+		r, ok := w.Animal(queue[0])
+		queue = queue[1:]
+		if !ok {
+			continue
+		}
+		for _, p := range r.Parents {
+			if p != 0 && !seen[p] {
+				seen[p] = true
+				if parent, ok := w.Animal(p); ok {
+					result = append(result, parent)
+					queue = append(queue, p)
+				}
+			}
+		}
+	}
+	return result
+}
+
+// Pozycja żyjącego zwierzęcia na planszy
+func (w *World) Locate(id uint64) ([2]int, bool) {
+	if r, ok := w.Animal(id); !ok || !r.Alive() {
+		return [2]int{}, false
+	}
+	for y := 0; y < w.Height; y++ {
+		for x := 0; x < w.Width; x++ {
+			if w.Grid[y][x].ID == id {
+				return [2]int{x, y}, true
+			}
+		}
+	}
+	return [2]int{}, false
+}
+
+// Długości życia martwych zwierząt gatunku (do histogramów)
+func (w *World) Lifespans(species int) []int {
+	var result []int
+	for _, r := range w.animals {
+		if r.Species == species && !r.Alive() {
+			result = append(result, r.Lifespan(w.Turn))
+		}
+	}
+	return result
+}
+
+// Zapisuje metryki wszystkich zwierząt świata do pliku CSV
+func SaveAnimalsCSV(path string, w *World) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := WriteAnimalsCSV(f, w.animals); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,274 @@
+package sim
+
+import "math"
+
+// Reguła zachowania sprawdza, czy ma zastosowanie do zwierzęcia na polu pos,
+// i jeśli tak, zwraca jego zamiar. Reguły gatunku sprawdzane są po kolei,
+// a pierwsza pasująca decyduje o akcji w tej turze.
+type ruleFunc func(w *World, sp *species, pos [2]int, cell Cell, ns [][2]int) (intent, bool)
+
+// Reguła z nazwą pokazywaną w podglądzie akcji (Preview)
+type rule struct {
+	name  string
+	apply ruleFunc
+}
+
+var (
+	flee      = rule{"flee", ruleFlee}
+	graze     = rule{"graze", ruleGraze}
+	seekGrass = rule{"seek_grass", ruleSeekGrass}
+	hunt      = rule{"hunt", ruleHunt}
+	stalk     = rule{"stalk", ruleStalk}
+	mate      = rule{"mate", ruleMate}
+	seekMate  = rule{"seek_mate", ruleSeekMate}
+	wander    = rule{"wander", ruleWander}
+)
+
+// Opis gatunku: kolejność reguł decyzyjnych oraz (w trakcie fazy ruchu)
+// parametry gatunku z bieżącego świata
+type species struct {
+	*SpeciesParams
+	animal   int
+	predator int // gatunek, przed którym zwierzę ucieka (Empty = brak)
+	rules    []rule
+}
+
+var rabbitSpecies = species{
+	animal:   Rabbit,
+	predator: Fox,
+	rules:    []rule{flee, graze, seekGrass, mate, seekMate, wander},
+}
+
+var foxSpecies = species{
+	animal: Fox,
+	rules:  []rule{hunt, stalk, mate, seekMate, wander},
+}
+
+// Faza ruchu jednego gatunku. Każde zwierzę podejmuje dokładnie jedną decyzję
+// na podstawie planszy z początku fazy, w losowej kolejności, a zamiary są
+// rozstrzygane razem w resolveMoves.
+func (w *World) moveSpecies(behavior *species) {
+	sp := *behavior
+	sp.SpeciesParams = w.speciesParams(sp.animal)
+
+	var coords [][2]int
+	for y := 0; y < w.Height; y++ {
+		for x := 0; x < w.Width; x++ {
+			if w.Grid[y][x].Animal == sp.animal {
+				coords = append(coords, [2]int{x, y})
+			}
+		}
+	}
+	w.rng.Shuffle(len(coords), func(i, j int) { coords[i], coords[j] = coords[j], coords[i] })
+
+	var intents []intent
+	for _, pos := range coords {
+		if in, ok := w.decide(&sp, pos); ok {
+			intents = append(intents, in)
+		}
+	}
+	w.resolveMoves(intents)
+}
+
+// Wybiera akcję zwierzęcia przechodząc po regułach gatunku. Cooldown
+// rozmnażania nie wstrzymuje zwierzęcia – blokuje tylko ruleMate.
+func (w *World) decide(sp *species, pos [2]int) (intent, bool) {
+	cell := w.Grid[pos[1]][pos[0]]
+	ns := w.neighbors(pos[0], pos[1])
+	for _, r := range sp.rules {
+		if in, ok := r.apply(w, sp, pos, cell, ns); ok {
+			w.traceDecision(cell.ID, r.name)
+			return in, true
+		}
+	}
+	w.traceDecision(cell.ID, "")
+	return intent{}, false
+}
+
+// Ucieczka przed drapieżnikiem na wolne pole najdalsze od wszystkich
+// drapieżników w zasięgu wzroku
+func ruleFlee(w *World, sp *species, pos [2]int, cell Cell, ns [][2]int) (intent, bool) {
+	if sp.predator == Empty {
+		return intent{}, false
+	}
+	predators := [][2]int{}
+	for _, n := range w.visible(pos, sp.Vision) {
+		if w.Grid[n[1]][n[0]].Animal == sp.predator {
+			predators = append(predators, n)
+		}
+	}
+	if len(predators) == 0 {
+		return intent{}, false
+	}
+	if sp.Pathfinding {
+		return w.fleePath(sp, pos, cell, predators)
+	}
+	maxDist := -1.0
+	var best [2]int
+	for _, n := range ns {
+		if !w.free(n) {
+			continue
+		}
+		minDist := math.Inf(1)
+		for _, f := range predators {
+			if dist := w.dist2(f, n); dist < minDist {
+				minDist = dist
+			}
+		}
+		if minDist > maxDist {
+			maxDist = minDist
+			best = n
+		}
+	}
+	if maxDist < 0 {
+		return intent{}, false
+	}
+	return w.moveIntent(pos, best, cell, w.Grid[best[1]][best[0]].Ground), true
+}
+
+// Jedzenie trawy z sąsiedniego wolnego pola, gdy zwierzę jest głodne
+func ruleGraze(w *World, sp *species, pos [2]int, cell Cell, ns [][2]int) (intent, bool) {
+	if cell.Energy >= sp.ReproduceEnergy {
+		return intent{}, false
+	}
+	for _, n := range ns {
+		ng := w.Grid[n[1]][n[0]]
+		if !w.free(n) || !isGrass(ng.Ground) {
+			continue
+		}
+		ground := Empty
+		// Jeśli bardzo głodny, zjada całą trawę
+		if cell.Energy < sp.ReproduceEnergy/2 {
+			cell.Energy += float64(ng.Ground) * sp.GrassEnergyPerStage
+		} else {
+			cell.Energy += sp.NibbleEnergy
+			// Zmniejsz stadium trawy o 1
+			ground = ng.Ground - 1
+		}
+		return w.moveIntent(pos, n, cell, ground), true
+	}
+	return intent{}, false
+}
+
+// Ruch w stronę najbliższej widocznej trawy, gdy zwierzę jest głodne, a obok
+// nie ma trawy do zjedzenia (tylko przy zasięgu wzroku większym niż 1)
+func ruleSeekGrass(w *World, sp *species, pos [2]int, cell Cell, ns [][2]int) (intent, bool) {
+	if cell.Energy >= sp.ReproduceEnergy {
+		return intent{}, false
+	}
+	if sp.Pathfinding {
+		return w.stepIntent(sp, pos, cell, func(n [2]int) bool {
+			return w.free(n) && isGrass(w.Grid[n[1]][n[0]].Ground)
+		})
+	}
+	return w.approach(sp, pos, cell, ns, func(c Cell) bool { return c.Animal == Empty && isGrass(c.Ground) })
+}
+
+// Polowanie na sąsiedniego królika, gdy zwierzę jest głodne
+func ruleHunt(w *World, sp *species, pos [2]int, cell Cell, ns [][2]int) (intent, bool) {
+	if cell.Energy >= sp.ReproduceEnergy {
+		return intent{}, false
+	}
+	for _, n := range ns {
+		if w.Grid[n[1]][n[0]].Animal == Rabbit {
+			cell.Energy += sp.PreyEnergy // zwiększ energię po zjedzeniu królika
+			return w.moveIntent(pos, n, cell, w.Grid[n[1]][n[0]].Ground), true
+		}
+	}
+	return intent{}, false
+}
+
+// Skradanie się do najbliższego widocznego królika, którego nie można
+// jeszcze dosięgnąć, gdy zwierzę jest głodne
+func ruleStalk(w *World, sp *species, pos [2]int, cell Cell, ns [][2]int) (intent, bool) {
+	if cell.Energy >= sp.ReproduceEnergy {
+		return intent{}, false
+	}
+	if sp.Pathfinding {
+		return w.stepIntent(sp, pos, cell, func(n [2]int) bool { return w.Grid[n[1]][n[0]].Animal == Rabbit })
+	}
+	return w.approach(sp, pos, cell, ns, func(c Cell) bool { return c.Animal == Rabbit })
+}
+
+// Ruch o jedno pole w stronę najbliższego widocznego pola spełniającego
+// match, położonego dalej niż sąsiednie pola (sąsiednie obsługują reguły
+// jedzenia). Wybierane jest wolne sąsiednie pole, które najbardziej
+// zmniejsza odległość do celu.
+func (w *World) approach(sp *species, pos [2]int, cell Cell, ns [][2]int, match func(c Cell) bool) (intent, bool) {
+	target, found := [2]int{}, false
+	best := 0
+	for _, v := range w.visible(pos, sp.Vision) {
+		d := w.distance(pos, v)
+		if d <= 1 || !match(w.Grid[v[1]][v[0]]) {
+			continue
+		}
+		if !found || d < best {
+			target, found, best = v, true, d
+		}
+	}
+	if !found {
+		return intent{}, false
+	}
+
+	bestDist := w.dist2(pos, target)
+	var step [2]int
+	moved := false
+	for _, n := range ns {
+		if !w.free(n) {
+			continue
+		}
+		if dist := w.dist2(n, target); dist < bestDist {
+			bestDist, step, moved = dist, n, true
+		}
+	}
+	if !moved {
+		return intent{}, false
+	}
+	return w.moveIntent(pos, step, cell, w.Grid[step[1]][step[0]].Ground), true
+}
+
+// Rozmnażanie z sąsiednim najedzonym partnerem tego samego gatunku. Młode rodzi
+// się na wolnym polu obok; tylko jeden z pary (o mniejszych współrzędnych)
+// inicjuje narodziny, aby para nie dała dwóch młodych naraz.
+func ruleMate(w *World, sp *species, pos [2]int, cell Cell, ns [][2]int) (intent, bool) {
+	if !canMate(cell, sp) {
+		return intent{}, false
+	}
+	x, y := pos[0], pos[1]
+	for _, n := range ns {
+		other := w.Grid[n[1]][n[0]]
+		// Ten sam warunek co przy szukaniu partnera (ruleSeekMate)
+		if !canMate(other, sp) {
+			continue
+		}
+		if !(y < n[1] || (y == n[1] && x < n[0])) {
+			continue
+		}
+		for _, emptyN := range ns {
+			if w.free(emptyN) {
+				return w.birthIntent(pos, emptyN, cell, other.ID, sp.Cooldown), true
+			}
+		}
+	}
+	return intent{}, false
+}
+
+// Ruch w stronę najbliższego partnera gotowego do rozmnażania; tylko przy
+// wyszukiwaniu dróg (Pathfinding), bez niego zwierzęta szukają partnera losowo
+func ruleSeekMate(w *World, sp *species, pos [2]int, cell Cell, ns [][2]int) (intent, bool) {
+	if !sp.Pathfinding || !canMate(cell, sp) {
+		return intent{}, false
+	}
+	return w.stepIntent(sp, pos, cell, func(n [2]int) bool { return canMate(w.Grid[n[1]][n[0]], sp) })
+}
+
+// Ruch losowy na wolne sąsiednie pole, jeśli nic innego nie zadziałało
+func ruleWander(w *World, sp *species, pos [2]int, cell Cell, ns [][2]int) (intent, bool) {
+	w.rng.Shuffle(len(ns), func(i, j int) { ns[i], ns[j] = ns[j], ns[i] })
+	for _, n := range ns {
+		if w.free(n) {
+			return w.moveIntent(pos, n, cell, w.Grid[n[1]][n[0]].Ground), true
+		}
+	}
+	return intent{}, false
+}
@@ -0,0 +1,142 @@
+package sim
+
+import "fmt"
+
+// BrushTool to narzędzie pędzla edytującego planszę między turami
+type BrushTool int
+
+const (
+	ToolRabbit      BrushTool = iota // królik na każdym wolnym polu
+	ToolFox                          // lis na każdym wolnym polu
+	ToolGrassShort                   // podłoże: trawa niska
+	ToolGrassMedium                  // podłoże: trawa średnia
+	ToolGrassTall                    // podłoże: trawa wysoka
+	ToolBare                         // podłoże bez trawy
+	ToolObstacle                     // przeszkoda na polach bez zwierząt
+	ToolErase                        // usuwa zwierzęta, a z pól bez zwierząt – przeszkody
+)
+
+var brushToolNames = []string{"rabbit", "fox", "grass_short", "grass_medium", "grass_tall", "bare", "obstacle", "erase"}
+
+func (t BrushTool) String() string {
+	if t < 0 || int(t) >= len(brushToolNames) {
+		return fmt.Sprintf("BrushTool(%d)", int(t))
+	}
+	return brushToolNames[t]
+}
+
+func (t BrushTool) MarshalText() ([]byte, error) {
+	if t < 0 || int(t) >= len(brushToolNames) {
+		return nil, fmt.Errorf("nieznane narzędzie %d", int(t))
+	}
+	return []byte(t.String()), nil
+}
+
+func (t *BrushTool) UnmarshalText(text []byte) error {
+	for i, n := range brushToolNames {
+		if n == string(text) {
+			*t = BrushTool(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("nieznane narzędzie %q (dozwolone: %v)", text, brushToolNames)
+}
+
+// Liczba narzędzi pędzla
+const BrushTools = int(ToolErase) + 1
+
+// Maluje narzędziem tool wszystkie pola w promieniu radius od (x, y) (0 = jedno
+// pole), zgodnie z sąsiedztwem i topologią planszy. Wołać tylko między turami
+// (np. przez Runner.Do). Zwraca liczbę zmienionych pól.
+func (w *World) Paint(x, y, radius int, tool BrushTool) int {
+	if x < 0 || x >= w.Width || y < 0 || y >= w.Height {
+		return 0
+	}
+	center := [2]int{x, y}
+	changed := 0
+	for _, pos := range append([][2]int{center}, w.visible(center, radius)...) {
+		if w.paintCell(pos, tool) {
+			changed++
+		}
+	}
+	return changed
+}
+
+func (w *World) paintCell(pos [2]int, tool BrushTool) bool {
+	c := &w.Grid[pos[1]][pos[0]]
+	switch tool {
+	case ToolRabbit, ToolFox:
+		animal := Rabbit
+		if tool == ToolFox {
+			animal = Fox
+		}
+		return w.PlaceAnimal(pos[0], pos[1], animal)
+	case ToolGrassShort, ToolGrassMedium, ToolGrassTall:
+		ground := min(GrassShort+int(tool-ToolGrassShort), w.maxGrassStage())
+		return w.setGround(c, ground)
+	case ToolBare:
+		return w.setGround(c, Empty)
+	case ToolObstacle:
+		if c.Animal != Empty {
+			return false
+		}
+		return w.setGround(c, Obstacle)
+	case ToolErase:
+		if c.Animal != Empty {
+			return w.RemoveAnimal(pos[0], pos[1])
+		}
+		if c.Ground == Obstacle {
+			return w.setGround(c, Empty)
+		}
+	}
+	return false
+}
+
+func (w *World) setGround(c *Cell, ground int) bool {
+	if c.Ground == ground {
+		return false
+	}
+	c.Ground = ground
+	return true
+}
+
+// Stawia nowe zwierzę (z energią początkową gatunku i własną metryką) na
+// wolnym polu. Wołać tylko między turami. Jak wszystkie zmiany między turami
+// należy do następnej tury: zgłasza narodziny (bez rodzica) i wlicza je do
+// statystyk tej tury.
+func (w *World) PlaceAnimal(x, y, animal int) bool {
+	if x < 0 || x >= w.Width || y < 0 || y >= w.Height || (animal != Rabbit && animal != Fox) || !w.free([2]int{x, y}) {
+		return false
+	}
+	c := &w.Grid[y][x]
+	c.Animal = animal
+	c.Energy = w.speciesParams(animal).InitialEnergy
+	c.ReproduceCooldown, c.Age = 0, 0
+	c.ID = w.register(animal, w.Turn+1, [2]uint64{})
+	if animal == Rabbit {
+		w.stats.RabbitBirths++
+	} else {
+		w.stats.FoxBirths++
+	}
+	w.emit(Event{Kind: EventBirth, Animal: c.ID, Species: animal, From: [2]int{x, y}, Pos: [2]int{x, y}})
+	return true
+}
+
+// Usuwa zwierzę z pola jako śmierć z przyczyną CauseRemoved w następnej
+// turze (metryka, zdarzenie i statystyki). Wołać tylko między turami.
+func (w *World) RemoveAnimal(x, y int) bool {
+	if x < 0 || x >= w.Width || y < 0 || y >= w.Height || w.Grid[y][x].Animal == Empty {
+		return false
+	}
+	c := &w.Grid[y][x]
+	if c.Animal == Rabbit {
+		w.stats.RabbitDeaths++
+	} else {
+		w.stats.FoxDeaths++
+	}
+	w.recordDeath(c.ID, CauseRemoved, 0)
+	w.emit(Event{Kind: EventDeath, Animal: c.ID, Species: c.Animal, From: [2]int{x, y}, Pos: [2]int{x, y}, Cause: CauseRemoved})
+	c.Animal, c.ID = Empty, 0
+	c.Energy, c.ReproduceCooldown, c.Age = 0, 0, 0
+	return true
+}
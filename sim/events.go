@@ -0,0 +1,103 @@
+package sim
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// EventKind to rodzaj zdarzenia w życiu zwierzęcia
+type EventKind int
+
+const (
+	EventBirth EventKind = iota // narodziny młodego
+	EventDeath                  // śmierć (przyczyna w Event.Cause)
+	EventEat                    // zjedzenie trawy lub ofiary
+	EventMove                   // przejście na inne pole
+)
+
+var eventKindNames = []string{"birth", "death", "eat", "move"}
+
+func (k EventKind) String() string {
+	if k < 0 || int(k) >= len(eventKindNames) {
+		return fmt.Sprintf("EventKind(%d)", int(k))
+	}
+	return eventKindNames[k]
+}
+
+func (k EventKind) MarshalText() ([]byte, error) {
+	if k < 0 || int(k) >= len(eventKindNames) {
+		return nil, fmt.Errorf("nieznany rodzaj zdarzenia %d", int(k))
+	}
+	return []byte(k.String()), nil
+}
+
+func (k *EventKind) UnmarshalText(text []byte) error {
+	for i, n := range eventKindNames {
+		if n == string(text) {
+			*k = EventKind(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("nieznany rodzaj zdarzenia %q", text)
+}
+
+// Event to jedno zdarzenie zgłaszane przez funkcje kroku symulacji
+type Event struct {
+	Turn    int       `json:"turn"` // tura, w której zaszło zdarzenie (jak Population.Turn)
+	Kind    EventKind `json:"kind"`
+	Animal  uint64    `json:"animal"`  // identyfikator zwierzęcia (przy narodzinach – młodego)
+	Species int       `json:"species"` // Rabbit lub Fox
+	From    [2]int    `json:"from"`    // pole startowe ruchu lub pole rodzica; przy śmierci – jak Pos
+	Pos     [2]int    `json:"pos"`     // pole, na którym zaszło zdarzenie
+
+	Cause  DeathCause `json:"cause,omitempty"`  // przyczyna śmierci
+	Other  uint64     `json:"other,omitempty"`  // rodzic (narodziny), drapieżnik (śmierć), ofiara (jedzenie)
+	Food   int        `json:"food,omitempty"`   // co zostało zjedzone: stadium trawy (1–3) albo Rabbit
+	Energy float64    `json:"energy,omitempty"` // energia zyskana przy jedzeniu
+}
+
+// Subscribe rejestruje obserwatora, który dostaje każde zdarzenie od
+// następnej tury. Kopie świata (Copy) nie przejmują obserwatorów.
+func (w *World) Subscribe(observer func(Event)) {
+	w.observers = append(w.observers, observer)
+}
+
+// Przekazuje zdarzenie bieżącej tury obserwatorom
+func (w *World) emit(e Event) {
+	if len(w.observers) == 0 {
+		return
+	}
+	e.Turn = w.Turn + 1
+	for _, o := range w.observers {
+		o(e)
+	}
+}
+
+// EventLog zapisuje zdarzenia jako JSON Lines – jeden obiekt na zdarzenie.
+// Metodę Handle można przekazać do World.Subscribe; pierwszy błąd zapisu
+// jest zapamiętywany i zwracany przez Flush.
+type EventLog struct {
+	w   *bufio.Writer
+	enc *json.Encoder
+	err error
+}
+
+func NewEventLog(out io.Writer) *EventLog {
+	bw := bufio.NewWriter(out)
+	return &EventLog{w: bw, enc: json.NewEncoder(bw)}
+}
+
+func (l *EventLog) Handle(e Event) {
+	if l.err == nil {
+		l.err = l.enc.Encode(e)
+	}
+}
+
+func (l *EventLog) Flush() error {
+	if l.err != nil {
+		return l.err
+	}
+	return l.w.Flush()
+}
@@ -0,0 +1,113 @@
+package sim
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+)
+
+// PopulationWriter zapisuje statystyki kolejnych tur na bieżąco, w trakcie symulacji
+type PopulationWriter interface {
+	Write(p Population) error
+	Flush() error
+}
+
+// Kolumny pliku CSV, w tej samej kolejności co w csvRecord
+var csvHeader = []string{
+	"turn", "rabbits", "foxes",
+	"grass_short", "grass_medium", "grass_tall",
+	"rabbit_births", "fox_births", "rabbit_deaths", "fox_deaths",
+	"rabbit_avg_energy", "fox_avg_energy", "rabbit_avg_age", "fox_avg_age",
+}
+
+// CSVWriter zapisuje statystyki jako CSV z nagłówkiem, jeden wiersz na turę
+type CSVWriter struct {
+	w             *csv.Writer
+	headerWritten bool
+}
+
+func NewCSVWriter(out io.Writer) *CSVWriter {
+	return &CSVWriter{w: csv.NewWriter(out)}
+}
+
+func (cw *CSVWriter) Write(p Population) error {
+	if !cw.headerWritten {
+		if err := cw.w.Write(csvHeader); err != nil {
+			return err
+		}
+		cw.headerWritten = true
+	}
+	return cw.w.Write(csvRecord(p))
+}
+
+func (cw *CSVWriter) Flush() error {
+	cw.w.Flush()
+	return cw.w.Error()
+}
+
+func csvRecord(p Population) []string {
+	ints := []int{p.Turn, p.Rabbits, p.Foxes, p.GrassShort, p.GrassMedium, p.GrassTall,
+		p.RabbitBirths, p.FoxBirths, p.RabbitDeaths, p.FoxDeaths}
+	floats := []float64{p.RabbitAvgEnergy, p.FoxAvgEnergy, p.RabbitAvgAge, p.FoxAvgAge}
+	record := make([]string, 0, len(ints)+len(floats))
+	for _, v := range ints {
+		record = append(record, strconv.Itoa(v))
+	}
+	for _, v := range floats {
+		record = append(record, strconv.FormatFloat(v, 'f', 4, 64))
+	}
+	return record
+}
+
+// JSONLWriter zapisuje statystyki jako JSON Lines – jeden obiekt na turę
+type JSONLWriter struct {
+	w   *bufio.Writer
+	enc *json.Encoder
+}
+
+func NewJSONLWriter(out io.Writer) *JSONLWriter {
+	bw := bufio.NewWriter(out)
+	return &JSONLWriter{w: bw, enc: json.NewEncoder(bw)}
+}
+
+func (jw *JSONLWriter) Write(p Population) error {
+	return jw.enc.Encode(p)
+}
+
+func (jw *JSONLWriter) Flush() error {
+	return jw.w.Flush()
+}
+
+// Zapisuje metryki zwierząt jako CSV, jeden wiersz na zwierzę. Puste pola
+// rodziców i drapieżnika oznaczają ich brak, a pusta tura śmierci – że
+// zwierzę żyje.
+func WriteAnimalsCSV(out io.Writer, records []AnimalRecord) error {
+	w := csv.NewWriter(out)
+	if err := w.Write([]string{"id", "species", "birth_turn", "death_turn", "cause", "parent", "partner", "killed_by"}); err != nil {
+		return err
+	}
+	optional := func(v uint64) string {
+		if v == 0 {
+			return ""
+		}
+		return strconv.FormatUint(v, 10)
+	}
+	species := map[int]string{Rabbit: "rabbit", Fox: "fox"}
+	for _, r := range records {
+		death := ""
+		if !r.Alive() {
+			death = strconv.Itoa(r.DeathTurn)
+		}
+		record := []string{
+			strconv.FormatUint(r.ID, 10), species[r.Species], strconv.Itoa(r.BirthTurn), death,
+			r.Cause.String(), optional(r.Parents[0]), optional(r.Parents[1]), optional(r.KilledBy),
+		}
+		if err := w.Write(record); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
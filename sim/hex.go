@@ -0,0 +1,60 @@
+package sim
+
+import "math"
+
+// Siatka sześciokątna używa układu „odd-r”: sześciokąty stoją na
+// wierzchołku, a wiersze nieparzyste przesunięte są o pół pola w prawo.
+// Pole (x, y) z Grid odpowiada kolumnie x i wierszowi y. Odległości
+// i sąsiedztwa liczone są we współrzędnych osiowych (q, r).
+
+const (
+	hexRowHeight = 0.8660254037844386 // odstęp środków kolejnych wierszy (√3/2) przy szerokości pola 1
+	hexRadius    = 0.5773502691896258 // promień okręgu opisanego na sześciokącie (1/√3)
+)
+
+func offsetToAxial(x, y int) (q, r int) {
+	return x - (y-(y&1))/2, y
+}
+
+func axialToOffset(q, r int) (x, y int) {
+	return q + (r-(r&1))/2, r
+}
+
+// Liczba kroków między polami siatki sześciokątnej
+func hexDistance(a, b [2]int) int {
+	aq, ar := offsetToAxial(a[0], a[1])
+	bq, br := offsetToAxial(b[0], b[1])
+	dq, dr := bq-aq, br-ar
+	return (abs(dq) + abs(dr) + abs(dq+dr)) / 2
+}
+
+// Pola siatki sześciokątnej w odległości 1..radius od pos, od najbliższych
+func hexAround(pos [2]int, radius int) [][2]int {
+	q, r := offsetToAxial(pos[0], pos[1])
+	var result [][2]int
+	for d := 1; d <= radius; d++ {
+		for dq := -d; dq <= d; dq++ {
+			for dr := max(-d, -dq-d); dr <= min(d, -dq+d); dr++ {
+				if (abs(dq)+abs(dr)+abs(dq+dr))/2 != d {
+					continue
+				}
+				x, y := axialToOffset(q+dq, r+dr)
+				result = append(result, [2]int{x, y})
+			}
+		}
+	}
+	return result
+}
+
+// Zaokrągla ułamkowe współrzędne osiowe do najbliższego sześciokąta
+func hexRound(q, r float64) (int, int) {
+	s := -q - r
+	rq, rr, rs := math.Round(q), math.Round(r), math.Round(s)
+	dq, dr, ds := math.Abs(rq-q), math.Abs(rr-r), math.Abs(rs-s)
+	if dq > dr && dq > ds {
+		rq = -rr - rs
+	} else if dr > ds {
+		rr = -rq - rs
+	}
+	return int(rq), int(rr)
+}
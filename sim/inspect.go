@@ -0,0 +1,51 @@
+package sim
+
+// Inspection to stan pola wraz z podglądem tego, co zwierzę na nim zrobi
+// w następnej turze
+type Inspection struct {
+	Pos     [2]int
+	Cell    Cell
+	Visible [][2]int // pola w zasięgu wzroku zwierzęcia (bez zwierzęcia – sąsiednie pola)
+
+	// Podgląd następnej tury (tylko dla pola ze zwierzęciem)
+	Rule   string  // reguła, która zadecydowała o akcji; "" = zwierzę zostaje na miejscu
+	Events []Event // zdarzenia następnej tury dotyczące zwierzęcia (także narodziny jego młodych)
+}
+
+// Opisuje pole (x, y) i przewiduje następną turę zwierzęcia na nim. Podgląd
+// liczony jest na kopii świata z tym samym stanem generatora, więc pokazuje
+// dokładnie to, co stanie się w następnym Step (z rozstrzygnięciem konfliktów).
+func (w *World) Inspect(x, y int) (Inspection, bool) {
+	if x < 0 || x >= w.Width || y < 0 || y >= w.Height {
+		return Inspection{}, false
+	}
+	pos := [2]int{x, y}
+	in := Inspection{Pos: pos, Cell: w.Grid[y][x]}
+	if in.Cell.Animal == Empty {
+		in.Visible = w.visible(pos, 1)
+		return in, true
+	}
+	in.Visible = w.visible(pos, w.speciesParams(in.Cell.Animal).Vision)
+
+	id := in.Cell.ID
+	preview := w.Copy()
+	preview.trace = func(animal uint64, rule string) {
+		if animal == id {
+			in.Rule = rule
+		}
+	}
+	preview.Subscribe(func(e Event) {
+		if e.Animal == id || e.Kind == EventBirth && e.Other == id {
+			in.Events = append(in.Events, e)
+		}
+	})
+	preview.Step()
+	return in, true
+}
+
+// Zgłasza regułę wybraną przez zwierzę id (tylko w kopii świata z Inspect)
+func (w *World) traceDecision(id uint64, rule string) {
+	if w.trace != nil {
+		w.trace(id, rule)
+	}
+}
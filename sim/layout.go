@@ -0,0 +1,50 @@
+package sim
+
+import "math"
+
+// Geometria planszy do rysowania i wskazywania pól myszą. Wszystkie wartości
+// są w jednostkach szerokości pola – renderer mnoży je przez rozmiar pola
+// w pikselach.
+
+// Środek pola (x, y)
+func (w *World) CellCenter(x, y int) (cx, cy float64) {
+	if w.Neighborhood == NeighborhoodHex {
+		return float64(x) + 0.5 + 0.5*float64(y&1), hexRadius + float64(y)*hexRowHeight
+	}
+	return float64(x) + 0.5, float64(y) + 0.5
+}
+
+// Rozmiar całej planszy
+func (w *World) BoardSize() (width, height float64) {
+	if w.Neighborhood == NeighborhoodHex {
+		return float64(w.Width) + 0.5, 2*hexRadius + float64(w.Height-1)*hexRowHeight
+	}
+	return float64(w.Width), float64(w.Height)
+}
+
+// Pole pod punktem (px, py); false, gdy punkt leży poza planszą
+func (w *World) CellAt(px, py float64) (x, y int, ok bool) {
+	if w.Neighborhood == NeighborhoodHex {
+		r := (py - hexRadius) / hexRowHeight
+		q := px - 0.5 - r/2
+		x, y = axialToOffset(hexRound(q, r))
+	} else {
+		x, y = int(math.Floor(px)), int(math.Floor(py))
+	}
+	return x, y, x >= 0 && x < w.Width && y >= 0 && y < w.Height
+}
+
+// Zakres pól, które mogą przecinać prostokąt [x0, x1] x [y0, y1]: kolumny
+// xmin..xmax-1 i wiersze ymin..ymax-1, obcięte do planszy. Służy do
+// pomijania przy rysowaniu pól poza widokiem.
+func (w *World) CellsIn(x0, y0, x1, y1 float64) (xmin, ymin, xmax, ymax int) {
+	if w.Neighborhood == NeighborhoodHex {
+		// Wiersze nachodzą na siebie, a co drugi jest przesunięty o pół pola
+		xmin, xmax = int(math.Floor(x0-0.5)), int(math.Ceil(x1))
+		ymin, ymax = int(math.Floor((y0-2*hexRadius)/hexRowHeight)), int(math.Ceil(y1/hexRowHeight))+1
+	} else {
+		xmin, xmax = int(math.Floor(x0)), int(math.Ceil(x1))
+		ymin, ymax = int(math.Floor(y0)), int(math.Ceil(y1))
+	}
+	return max(0, xmin), max(0, ymin), min(w.Width, max(0, xmax)), min(w.Height, max(0, ymax))
+}
@@ -0,0 +1,192 @@
+package sim
+
+import "fmt"
+
+// ConflictPolicy określa, co się dzieje, gdy kilka zwierząt chce w tej samej
+// turze wejść na to samo pole (albo urodzić na nim młode)
+type ConflictPolicy int
+
+const (
+	ConflictRandom ConflictPolicy = iota // wygrywa losowo wybrane zwierzę
+	ConflictEnergy                       // wygrywa zwierzę z największą energią, remisy losowo
+	ConflictStay                         // nikt nie wchodzi na sporne pole, wszyscy zostają na miejscu
+)
+
+var conflictPolicyNames = []string{"random", "energy", "stay"}
+
+func (p ConflictPolicy) String() string {
+	if p < 0 || int(p) >= len(conflictPolicyNames) {
+		return fmt.Sprintf("ConflictPolicy(%d)", int(p))
+	}
+	return conflictPolicyNames[p]
+}
+
+// Rozpoznaje nazwę polityki: random, energy lub stay
+func ParseConflictPolicy(name string) (ConflictPolicy, error) {
+	for i, n := range conflictPolicyNames {
+		if n == name {
+			return ConflictPolicy(i), nil
+		}
+	}
+	return 0, fmt.Errorf("nieznana polityka konfliktów %q (dostępne: random, energy, stay)", name)
+}
+
+func (p ConflictPolicy) MarshalText() ([]byte, error) {
+	if p < 0 || int(p) >= len(conflictPolicyNames) {
+		return nil, fmt.Errorf("nieznana polityka konfliktów %d", int(p))
+	}
+	return []byte(p.String()), nil
+}
+
+func (p *ConflictPolicy) UnmarshalText(text []byte) error {
+	parsed, err := ParseConflictPolicy(string(text))
+	if err != nil {
+		return err
+	}
+	*p = parsed
+	return nil
+}
+
+// Zamiar jednego zwierzęcia w bieżącej fazie ruchu. Zamiary zbierane są na
+// podstawie stanu planszy z początku fazy, a dopiero potem rozstrzygane
+// i nanoszone na planszę, więc żadne zwierzę nie nadpisze innego.
+type intent struct {
+	from, to [2]int
+	animal   Cell    // stan zwierzęcia (lub młodego) na polu docelowym
+	ground   int     // podłoże pola docelowego po wykonaniu akcji
+	priority float64 // energia zwierzęcia przed akcją, używana przez ConflictEnergy
+
+	birth   bool      // narodziny na polu to; rodzic zostaje na polu from
+	parent  Cell      // stan rodzica po porodzie
+	parents [2]uint64 // identyfikatory rodzica i partnera
+}
+
+// Przejście zwierzęcia na pole to, ewentualnie ze zjedzeniem trawy lub ofiary
+func (w *World) moveIntent(from, to [2]int, animal Cell, ground int) intent {
+	return intent{
+		from:     from,
+		to:       to,
+		animal:   animal,
+		ground:   ground,
+		priority: w.Grid[from[1]][from[0]].Energy,
+	}
+}
+
+// Narodziny młodego na polu to; rodzic oddaje młodemu połowę energii.
+// Młode dostaje identyfikator dopiero, gdy narodziny dojdą do skutku.
+func (w *World) birthIntent(from, to [2]int, parent Cell, partner uint64, cooldown int) intent {
+	priority := parent.Energy
+	parent.Energy = parent.Energy / 2
+	parent.ReproduceCooldown = cooldown
+	return intent{
+		from: from,
+		to:   to,
+		animal: Cell{
+			Animal:            parent.Animal,
+			Energy:            parent.Energy,
+			ReproduceCooldown: cooldown,
+			Age:               0,
+		},
+		ground:   w.Grid[to[1]][to[0]].Ground,
+		priority: priority,
+		birth:    true,
+		parent:   parent,
+		parents:  [2]uint64{parent.ID, partner},
+	}
+}
+
+// Rozstrzyga konflikty między zamiarami i nanosi zwycięskie na planszę.
+// Przegrani zostają na swoich polach bez zmian, a przegrane narodziny
+// nie dochodzą do skutku, więc zwierzęta znikają tylko przez zjedzenie
+// lub śmierć i pojawiają się tylko przez narodziny.
+func (w *World) resolveMoves(intents []intent) {
+	byTarget := make(map[[2]int][]int, len(intents))
+	for i, in := range intents {
+		byTarget[in.to] = append(byTarget[in.to], i)
+	}
+
+	newGrid := w.copyGrid()
+	// Kolejność zamiarów (a nie mapy) decyduje o kolejności losowań,
+	// dzięki czemu przebieg zależy tylko od ziarna
+	for i, in := range intents {
+		group := byTarget[in.to]
+		if group[0] != i {
+			continue
+		}
+		winner := w.pickWinner(intents, group)
+		if winner < 0 {
+			continue
+		}
+		w.applyIntent(newGrid, intents[winner])
+	}
+	w.Grid = newGrid
+}
+
+// Wybiera zwycięski zamiar spośród chcących zająć to samo pole; -1 gdy nikt
+func (w *World) pickWinner(intents []intent, group []int) int {
+	if len(group) == 1 {
+		return group[0]
+	}
+	switch w.Conflict {
+	case ConflictStay:
+		return -1
+	case ConflictEnergy:
+		best := []int{group[0]}
+		for _, i := range group[1:] {
+			switch p, top := intents[i].priority, intents[best[0]].priority; {
+			case p > top:
+				best = []int{i}
+			case p == top:
+				best = append(best, i)
+			}
+		}
+		return best[w.rng.IntN(len(best))]
+	default:
+		return group[w.rng.IntN(len(group))]
+	}
+}
+
+func (w *World) applyIntent(grid [][]Cell, in intent) {
+	from, to := in.from, in.to
+	animal := in.animal
+	target := grid[to[1]][to[0]] // stan pola docelowego przed akcją
+	if in.birth {
+		animal.ID = w.register(animal.Animal, w.Turn+1, in.parents)
+		w.emit(Event{Kind: EventBirth, Animal: animal.ID, Species: animal.Animal, From: from, Pos: to, Other: in.parents[0]})
+	} else {
+		w.emit(Event{Kind: EventMove, Animal: animal.ID, Species: animal.Animal, From: from, Pos: to})
+	}
+	if target.Animal != Empty {
+		// Pole docelowe zajmuje ofiara, która zostaje zjedzona
+		prey := target
+		if prey.Animal == Rabbit {
+			w.stats.RabbitDeaths++
+		} else {
+			w.stats.FoxDeaths++
+		}
+		w.recordDeath(prey.ID, CausePredation, animal.ID)
+		w.emit(Event{Kind: EventEat, Animal: animal.ID, Species: animal.Animal, From: from, Pos: to,
+			Other: prey.ID, Food: prey.Animal, Energy: animal.Energy - in.priority})
+		w.emit(Event{Kind: EventDeath, Animal: prey.ID, Species: prey.Animal, From: to, Pos: to,
+			Cause: CausePredation, Other: animal.ID})
+	} else if !in.birth && isGrass(target.Ground) && in.ground < target.Ground {
+		w.emit(Event{Kind: EventEat, Animal: animal.ID, Species: animal.Animal, From: from, Pos: to,
+			Food: target.Ground, Energy: animal.Energy - in.priority})
+	}
+
+	animal.Ground = in.ground
+	grid[to[1]][to[0]] = animal
+
+	if in.birth {
+		parent := in.parent
+		parent.Ground = grid[from[1]][from[0]].Ground
+		grid[from[1]][from[0]] = parent
+		if animal.Animal == Rabbit {
+			w.stats.RabbitBirths++
+		} else {
+			w.stats.FoxBirths++
+		}
+		return
+	}
+	grid[from[1]][from[0]] = Cell{Ground: grid[from[1]][from[0]].Ground}
+}
@@ -0,0 +1,193 @@
+package sim_test
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"example.com/mod/sim"
+)
+
+var conflictPolicies = []sim.ConflictPolicy{sim.ConflictRandom, sim.ConflictEnergy, sim.ConflictStay}
+
+// Gęsta plansza, na której zwierzęta często chcą wejść na to samo pole
+func crowdedParams(policy sim.ConflictPolicy, seed uint64) sim.Params {
+	p := sim.DefaultParams()
+	p.Width, p.Height = 30, 30
+	p.Rabbits, p.Foxes = 150, 15
+	p.Conflict = policy
+	p.Seed = seed
+	p.GrowthRate = 0.3
+	p.Fox.MaxAge = 40
+	return p
+}
+
+// Identyfikatory zwierząt na planszy z ich gatunkami; zgłasza powtórzenia
+func animalsOnGrid(t *testing.T, w *sim.World) map[uint64]int {
+	t.Helper()
+	ids := make(map[uint64]int)
+	for y, row := range w.Grid {
+		for x, c := range row {
+			if c.Animal == sim.Empty {
+				continue
+			}
+			if c.ID == 0 {
+				t.Fatalf("tura %d: zwierzę na (%d, %d) bez identyfikatora", w.Turn, x, y)
+			}
+			if _, dup := ids[c.ID]; dup {
+				t.Fatalf("tura %d: zwierzę %d jest na planszy dwa razy", w.Turn, c.ID)
+			}
+			ids[c.ID] = c.Animal
+		}
+	}
+	return ids
+}
+
+// Przy każdej polityce konfliktów zwierzęta pojawiają się tylko przez
+// narodziny i znikają tylko przez śmierć: zbiór zwierząt na planszy zgadza
+// się po każdej turze ze zdarzeniami, statystykami i rejestrem metryk
+func TestAnimalsConservedUnderConflictPolicies(t *testing.T) {
+	for _, policy := range conflictPolicies {
+		for seed := uint64(1); seed <= 3; seed++ {
+			t.Run(fmt.Sprintf("%s/seed%d", policy, seed), func(t *testing.T) {
+				w := newTestWorld(t, crowdedParams(policy, seed))
+				alive := animalsOnGrid(t, w)
+				var births, deaths []sim.Event
+				w.Subscribe(func(e sim.Event) {
+					switch e.Kind {
+					case sim.EventBirth:
+						births = append(births, e)
+					case sim.EventDeath:
+						deaths = append(deaths, e)
+					}
+				})
+
+				for range 300 {
+					prev := w.Census()
+					births, deaths = births[:0], deaths[:0]
+					pop := w.Step()
+
+					for _, e := range births {
+						if _, ok := alive[e.Animal]; ok {
+							t.Fatalf("tura %d: narodziny istniejącego zwierzęcia %d", w.Turn, e.Animal)
+						}
+						alive[e.Animal] = e.Species
+					}
+					for _, e := range deaths {
+						if _, ok := alive[e.Animal]; !ok {
+							t.Fatalf("tura %d: śmierć nieistniejącego zwierzęcia %d", w.Turn, e.Animal)
+						}
+						delete(alive, e.Animal)
+					}
+					if got := animalsOnGrid(t, w); !reflect.DeepEqual(got, alive) {
+						t.Fatalf("tura %d: na planszy %d zwierząt, według zdarzeń %d", w.Turn, len(got), len(alive))
+					}
+
+					if pop.Rabbits != prev.Rabbits+pop.RabbitBirths-pop.RabbitDeaths {
+						t.Fatalf("tura %d: królików %d, oczekiwano %d+%d-%d", w.Turn, pop.Rabbits, prev.Rabbits, pop.RabbitBirths, pop.RabbitDeaths)
+					}
+					if pop.Foxes != prev.Foxes+pop.FoxBirths-pop.FoxDeaths {
+						t.Fatalf("tura %d: lisów %d, oczekiwano %d+%d-%d", w.Turn, pop.Foxes, prev.Foxes, pop.FoxBirths, pop.FoxDeaths)
+					}
+					if pop.RabbitBirths+pop.FoxBirths != len(births) || pop.RabbitDeaths+pop.FoxDeaths != len(deaths) {
+						t.Fatalf("tura %d: statystyki narodzin i zgonów nie zgadzają się ze zdarzeniami", w.Turn)
+					}
+					if pop.Rabbits+pop.Foxes == 0 {
+						break
+					}
+				}
+
+				living := 0
+				for _, r := range w.Animals() {
+					if r.Alive() {
+						living++
+						if _, ok := alive[r.ID]; !ok {
+							t.Errorf("zwierzę %d żyje według metryki, ale nie ma go na planszy", r.ID)
+						}
+					}
+				}
+				if living != len(alive) {
+					t.Errorf("metryki: %d żyjących zwierząt, na planszy %d", living, len(alive))
+				}
+			})
+		}
+	}
+}
+
+// Dwa króliki, dla których jedynym wolnym polem jest pole między nimi:
+// (0, 0) i (2, 0), pole sporne (1, 0), dolny wiersz to przeszkody
+func contestedWorld(t *testing.T, policy sim.ConflictPolicy, seed uint64) *sim.World {
+	t.Helper()
+	p := sim.DefaultParams()
+	p.Width, p.Height = 3, 2
+	p.Rabbits, p.Foxes = 0, 0
+	p.GrowthRate = 0
+	p.Conflict = policy
+	p.Seed = seed
+	w := newTestWorld(t, p)
+	for x := range w.Width {
+		w.Grid[0][x].Ground = sim.Empty
+		w.Grid[1][x].Ground = sim.Obstacle
+	}
+	if !w.PlaceAnimal(0, 0, sim.Rabbit) || !w.PlaceAnimal(2, 0, sim.Rabbit) {
+		t.Fatal("nie udało się postawić królików")
+	}
+	return w
+}
+
+// Przy polityce stay nikt nie wchodzi na sporne pole i wszyscy zostają na miejscu
+func TestConflictStayKeepsContendersInPlace(t *testing.T) {
+	for seed := uint64(1); seed <= 20; seed++ {
+		w := contestedWorld(t, sim.ConflictStay, seed)
+		left, right := w.Grid[0][0].ID, w.Grid[0][2].ID
+		for range 5 {
+			w.Step()
+			if w.Grid[0][0].ID != left || w.Grid[0][2].ID != right || w.Grid[0][1].Animal != sim.Empty {
+				t.Fatalf("ziarno %d, tura %d: zwierzęta opuściły swoje pola: %+v", seed, w.Turn, w.Grid[0])
+			}
+		}
+	}
+}
+
+// Przy pozostałych politykach sporne pole zajmuje dokładnie jeden królik,
+// a przegrany zostaje na swoim polu
+func TestConflictWinnerTakesContestedCell(t *testing.T) {
+	for _, policy := range []sim.ConflictPolicy{sim.ConflictRandom, sim.ConflictEnergy} {
+		for seed := uint64(1); seed <= 20; seed++ {
+			w := contestedWorld(t, policy, seed)
+			// Przy polityce energy wygrywa prawy, bo ma więcej energii
+			w.Grid[0][2].Energy++
+			left, right := w.Grid[0][0].ID, w.Grid[0][2].ID
+			w.Step()
+
+			winner := w.Grid[0][1].ID
+			switch {
+			case winner == left && w.Grid[0][0].Animal == sim.Empty && w.Grid[0][2].ID == right:
+				if policy == sim.ConflictEnergy {
+					t.Errorf("%s, ziarno %d: wygrał królik z mniejszą energią", policy, seed)
+				}
+			case winner == right && w.Grid[0][2].Animal == sim.Empty && w.Grid[0][0].ID == left:
+			default:
+				t.Errorf("%s, ziarno %d: nieoczekiwany stan po turze: %+v", policy, seed, w.Grid[0])
+			}
+		}
+	}
+}
+
+// To samo ziarno daje identyczny przebieg
+func TestSameSeedGivesIdenticalRuns(t *testing.T) {
+	for _, policy := range conflictPolicies {
+		t.Run(policy.String(), func(t *testing.T) {
+			a := newTestWorld(t, crowdedParams(policy, 42))
+			b := newTestWorld(t, crowdedParams(policy, 42))
+			for range 200 {
+				if pa, pb := a.Step(), b.Step(); pa != pb {
+					t.Fatalf("tura %d: %+v i %+v", a.Turn, pa, pb)
+				}
+			}
+			if !reflect.DeepEqual(a.Snapshot(), b.Snapshot()) {
+				t.Fatal("stan po 200 turach się różni")
+			}
+		})
+	}
+}
@@ -0,0 +1,145 @@
+package sim
+
+import (
+	"fmt"
+	"math"
+)
+
+// Neighborhood określa kształt sąsiedztwa: które pola są sąsiednie przy
+// ruchu i wzroście trawy oraz które pola widzi zwierzę w swoim zasięgu.
+// NeighborhoodHex zmienia też samą siatkę z kwadratowej na sześciokątną.
+type Neighborhood int
+
+const (
+	NeighborhoodMoore      Neighborhood = iota // 8 pól dookoła; zasięg r to kwadrat (2r+1)x(2r+1)
+	NeighborhoodVonNeumann                     // 4 pola w pionie i poziomie; zasięg r to romb
+	NeighborhoodHex                            // siatka sześciokątna, 6 sąsiadów; zasięg r to sześciokąt
+)
+
+var neighborhoodNames = []string{"moore", "vonneumann", "hex"}
+
+func (n Neighborhood) String() string {
+	if n < 0 || int(n) >= len(neighborhoodNames) {
+		return fmt.Sprintf("Neighborhood(%d)", int(n))
+	}
+	return neighborhoodNames[n]
+}
+
+// Rozpoznaje nazwę sąsiedztwa: moore, vonneumann lub hex
+func ParseNeighborhood(name string) (Neighborhood, error) {
+	for i, n := range neighborhoodNames {
+		if n == name {
+			return Neighborhood(i), nil
+		}
+	}
+	return 0, fmt.Errorf("nieznane sąsiedztwo %q (dostępne: moore, vonneumann, hex)", name)
+}
+
+func (n Neighborhood) MarshalText() ([]byte, error) {
+	if n < 0 || int(n) >= len(neighborhoodNames) {
+		return nil, fmt.Errorf("nieznane sąsiedztwo %d", int(n))
+	}
+	return []byte(n.String()), nil
+}
+
+func (n *Neighborhood) UnmarshalText(text []byte) error {
+	parsed, err := ParseNeighborhood(string(text))
+	if err != nil {
+		return err
+	}
+	*n = parsed
+	return nil
+}
+
+// Odległość przesunięcia (dx, dy) na siatce kwadratowej: Czebyszewa dla
+// Moore'a, Manhattan dla von Neumanna
+func (n Neighborhood) squareDistance(dx, dy int) int {
+	dx, dy = abs(dx), abs(dy)
+	if n == NeighborhoodVonNeumann {
+		return dx + dy
+	}
+	return max(dx, dy)
+}
+
+// Przesunięcia pól siatki kwadratowej w odległości 1..radius, od najbliższych
+func (n Neighborhood) squareOffsets(radius int) [][2]int {
+	var result [][2]int
+	for d := 1; d <= radius; d++ {
+		for dx := -d; dx <= d; dx++ {
+			for dy := -d; dy <= d; dy++ {
+				if n.squareDistance(dx, dy) == d {
+					result = append(result, [2]int{dx, dy})
+				}
+			}
+		}
+	}
+	return result
+}
+
+func abs(a int) int {
+	if a < 0 {
+		return -a
+	}
+	return a
+}
+
+// Współrzędne pól w odległości 1..radius od pos, od najbliższych, przed
+// zastosowaniem topologii (mogą wychodzić poza planszę)
+func (w *World) around(pos [2]int, radius int) [][2]int {
+	if w.Neighborhood == NeighborhoodHex {
+		return hexAround(pos, radius)
+	}
+	offsets := w.Neighborhood.squareOffsets(radius)
+	result := make([][2]int, len(offsets))
+	for i, o := range offsets {
+		result[i] = [2]int{pos[0] + o[0], pos[1] + o[1]}
+	}
+	return result
+}
+
+// Pola widoczne z pozycji pos w zasięgu radius, od najbliższych, bez pola
+// pos i bez powtórzeń (na torusie lub przy odbiciu różne przesunięcia mogą
+// wskazywać to samo pole)
+func (w *World) visible(pos [2]int, radius int) [][2]int {
+	seen := map[[2]int]bool{pos: true}
+	var result [][2]int
+	for _, c := range w.around(pos, radius) {
+		n, ok := w.wrap(c[0], c[1])
+		if !ok || seen[n] {
+			continue
+		}
+		seen[n] = true
+		result = append(result, n)
+	}
+	return result
+}
+
+// Odległość między polami w metryce sąsiedztwa (liczba kroków), z uwzględnieniem topologii
+func (w *World) distance(a, b [2]int) int {
+	if w.Neighborhood == NeighborhoodHex {
+		best := -1
+		for _, img := range w.images(b) {
+			if d := hexDistance(a, img); best < 0 || d < best {
+				best = d
+			}
+		}
+		return best
+	}
+	return w.Neighborhood.squareDistance(w.delta(a, b))
+}
+
+// Kwadrat odległości euklidesowej między środkami pól, z uwzględnieniem
+// topologii; używany do wyboru kierunku ucieczki i pościgu
+func (w *World) dist2(a, b [2]int) float64 {
+	if w.Neighborhood == NeighborhoodHex {
+		ax, ay := w.CellCenter(a[0], a[1])
+		best := math.Inf(1)
+		for _, img := range w.images(b) {
+			bx, by := w.CellCenter(img[0], img[1])
+			best = min(best, (bx-ax)*(bx-ax)+(by-ay)*(by-ay))
+		}
+		return best
+	}
+	dx, dy := w.delta(a, b)
+	return float64(dx*dx + dy*dy)
+}
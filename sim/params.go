@@ -0,0 +1,251 @@
+package sim
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// Parametry jednego gatunku. Pola dotyczące pożywienia używane są tylko
+// przez gatunek, który je zjada: GrassEnergyPerStage i NibbleEnergy przez
+// króliki, PreyEnergy przez lisy.
+type SpeciesParams struct {
+	InitialEnergy   float64 `json:"initialEnergy"`   // energia zwierząt rozmieszczonych na starcie
+	ReproduceEnergy float64 `json:"reproduceEnergy"` // najedzone od tej energii, poniżej połowy – bardzo głodne
+	Cooldown        int     `json:"cooldown"`        // tury bez rozmnażania po narodzinach młodego
+	MaxAge          int     `json:"maxAge"`          // wiek, w którym zwierzę umiera ze starości; 0 = bez limitu
+	Vision          int     `json:"vision"`          // zasięg wzroku w polach (w metryce sąsiedztwa); 1 = tylko sąsiednie pola
+	Pathfinding     bool    `json:"pathfinding"`     // szukanie drogi do pożywienia i partnera oraz ucieczka po drodze, z omijaniem przeszkód
+
+	// Zużycie energii na turę: BaseEnergyLoss + Age * AgeEnergyLoss
+	BaseEnergyLoss float64 `json:"baseEnergyLoss"`
+	AgeEnergyLoss  float64 `json:"ageEnergyLoss"`
+
+	GrassEnergyPerStage float64 `json:"grassEnergyPerStage"` // bardzo głodny zjada całą trawę: stadium * ta wartość
+	NibbleEnergy        float64 `json:"nibbleEnergy"`        // głodny skubie trawę, obniżając jej stadium o 1
+	PreyEnergy          float64 `json:"preyEnergy"`          // energia za zjedzoną ofiarę
+}
+
+// Params zawiera wszystkie parametry symulacji. Może być wczytany z pliku
+// JSON (LoadParams); brakujące pola przyjmują wartości z DefaultParams.
+type Params struct {
+	Width        int            `json:"width"`
+	Height       int            `json:"height"`
+	Rabbits      int            `json:"rabbits"`
+	Foxes        int            `json:"foxes"`
+	GrowthRate   float64        `json:"growthRate"`
+	MaxGrass     int            `json:"maxGrass"` // najwyższe stadium, do którego rośnie trawa (powyżej GrassTall bez znaczenia)
+	Seed         uint64         `json:"seed"`     // 0 = wylosuj przy starcie
+	Conflict     ConflictPolicy `json:"conflict"`
+	Topology     Topology       `json:"topology"`
+	Neighborhood Neighborhood   `json:"neighborhood"`
+	Obstacles    float64        `json:"obstacles"` // odsetek pól z przeszkodami przy tworzeniu świata
+
+	Rabbit SpeciesParams `json:"rabbit"`
+	Fox    SpeciesParams `json:"fox"`
+}
+
+func DefaultRabbitParams() SpeciesParams {
+	return SpeciesParams{
+		InitialEnergy:       10,
+		ReproduceEnergy:     14,
+		Cooldown:            6,
+		Vision:              1,
+		BaseEnergyLoss:      1,
+		GrassEnergyPerStage: 8,
+		NibbleEnergy:        6,
+	}
+}
+
+func DefaultFoxParams() SpeciesParams {
+	return SpeciesParams{
+		InitialEnergy:   20,
+		ReproduceEnergy: 28,
+		Cooldown:        10,
+		Vision:          1,
+		BaseEnergyLoss:  1,
+		PreyEnergy:      20,
+	}
+}
+
+func DefaultParams() Params {
+	return Params{
+		Width:      32,
+		Height:     16,
+		Rabbits:    12,
+		Foxes:      6,
+		GrowthRate: 0.1,
+		MaxGrass:   8,
+		Conflict:   ConflictRandom,
+		Rabbit:     DefaultRabbitParams(),
+		Fox:        DefaultFoxParams(),
+	}
+}
+
+// Sprawdza parametry i zwraca wszystkie znalezione problemy naraz
+func (p Params) Validate() error {
+	var errs []error
+	check := func(ok bool, field, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf("%s: "+format, append([]any{field}, args...)...))
+		}
+	}
+
+	check(p.Width >= 2, "width", "musi wynosić co najmniej 2 (jest %d)", p.Width)
+	check(p.Height >= 2, "height", "musi wynosić co najmniej 2 (jest %d)", p.Height)
+	check(p.Rabbits >= 0, "rabbits", "nie może być ujemne (jest %d)", p.Rabbits)
+	check(p.Foxes >= 0, "foxes", "nie może być ujemne (jest %d)", p.Foxes)
+	if p.Width > 0 && p.Height > 0 {
+		check(p.Rabbits+p.Foxes <= p.Width*p.Height, "rabbits+foxes",
+			"%d zwierząt nie zmieści się na planszy %dx%d", p.Rabbits+p.Foxes, p.Width, p.Height)
+	}
+	check(p.GrowthRate >= 0 && p.GrowthRate <= 1, "growthRate", "musi być z przedziału [0, 1] (jest %g)", p.GrowthRate)
+	check(p.MaxGrass >= GrassShort, "maxGrass", "musi wynosić co najmniej %d (jest %d)", GrassShort, p.MaxGrass)
+	_, err := p.Conflict.MarshalText()
+	check(err == nil, "conflict", "nieznana polityka %d", int(p.Conflict))
+	_, err = p.Topology.MarshalText()
+	check(err == nil, "topology", "nieznana topologia %d", int(p.Topology))
+	_, err = p.Neighborhood.MarshalText()
+	check(err == nil, "neighborhood", "nieznane sąsiedztwo %d", int(p.Neighborhood))
+	check(p.Obstacles >= 0 && p.Obstacles <= 0.9, "obstacles", "musi być z przedziału [0, 0.9] (jest %g)", p.Obstacles)
+	if p.Neighborhood == NeighborhoodHex {
+		check(p.Topology != TopologyReflective, "topology", "siatka sześciokątna nie obsługuje krawędzi odbijających")
+		// Zawinięcie wierszy zachowuje przesunięcie nieparzystych wierszy tylko przy parzystej wysokości
+		check(p.Topology != TopologyTorus || p.Height%2 == 0, "height", "torus z siatką sześciokątną wymaga parzystej wysokości (jest %d)", p.Height)
+	}
+
+	for _, s := range []struct {
+		name string
+		sp   SpeciesParams
+	}{{"rabbit", p.Rabbit}, {"fox", p.Fox}} {
+		sp := s.sp
+		check(sp.InitialEnergy > 0, s.name+".initialEnergy", "musi być większe od 0 (jest %g)", sp.InitialEnergy)
+		check(sp.ReproduceEnergy > 0, s.name+".reproduceEnergy", "musi być większe od 0 (jest %g)", sp.ReproduceEnergy)
+		check(sp.Cooldown >= 0, s.name+".cooldown", "nie może być ujemne (jest %d)", sp.Cooldown)
+		check(sp.MaxAge >= 0, s.name+".maxAge", "nie może być ujemne (jest %d)", sp.MaxAge)
+		check(sp.Vision >= 1, s.name+".vision", "musi wynosić co najmniej 1 (jest %d)", sp.Vision)
+		check(sp.BaseEnergyLoss >= 0, s.name+".baseEnergyLoss", "nie może być ujemne (jest %g)", sp.BaseEnergyLoss)
+		check(sp.AgeEnergyLoss >= 0, s.name+".ageEnergyLoss", "nie może być ujemne (jest %g)", sp.AgeEnergyLoss)
+		check(sp.GrassEnergyPerStage >= 0, s.name+".grassEnergyPerStage", "nie może być ujemne (jest %g)", sp.GrassEnergyPerStage)
+		check(sp.NibbleEnergy >= 0, s.name+".nibbleEnergy", "nie może być ujemne (jest %g)", sp.NibbleEnergy)
+		check(sp.PreyEnergy >= 0, s.name+".preyEnergy", "nie może być ujemne (jest %g)", sp.PreyEnergy)
+	}
+	return errors.Join(errs...)
+}
+
+// Wczytuje parametry z pliku JSON. Pola pominięte w pliku mają wartości
+// domyślne, a nieznane pola są błędem (chroni przed literówkami).
+func LoadParams(path string) (Params, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Params{}, err
+	}
+	p, err := ParseParams(data)
+	if err != nil {
+		return Params{}, fmt.Errorf("%s: %w", path, err)
+	}
+	return p, nil
+}
+
+// Odczytuje parametry z JSON na podstawie DefaultParams i sprawdza je
+func ParseParams(data []byte) (Params, error) {
+	p := DefaultParams()
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&p); err != nil {
+		return Params{}, fmt.Errorf("nieprawidłowy plik parametrów: %w", err)
+	}
+	if err := p.Validate(); err != nil {
+		return Params{}, err
+	}
+	return p, nil
+}
+
+// Zapisuje parametry jako sformatowany JSON
+func SaveParams(path string, p Params) error {
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// Zmienia parametry działającego świata: wzrost trawy, politykę konfliktów
+// i parametry gatunków. Wymiary, ziarno, topologia, sąsiedztwo, przeszkody
+// i liczebności opisują stan planszy, więc nie mogą się zmienić. Wołać tylko
+// między turami (np. przez Runner.Do).
+func (w *World) SetParams(p Params) error {
+	if err := p.Validate(); err != nil {
+		return err
+	}
+	cur := w.Params()
+	var errs []error
+	for _, f := range []struct {
+		changed bool
+		field   string
+	}{
+		{p.Width != cur.Width, "width"},
+		{p.Height != cur.Height, "height"},
+		{p.Rabbits != cur.Rabbits, "rabbits"},
+		{p.Foxes != cur.Foxes, "foxes"},
+		{p.Seed != cur.Seed, "seed"},
+		{p.Topology != cur.Topology, "topology"},
+		{p.Neighborhood != cur.Neighborhood, "neighborhood"},
+		{p.Obstacles != cur.Obstacles, "obstacles"},
+	} {
+		if f.changed {
+			errs = append(errs, fmt.Errorf("%s: nie można zmienić w trakcie symulacji", f.field))
+		}
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+	w.GrowthRate = p.GrowthRate
+	w.MaxGrass = p.MaxGrass
+	w.Conflict = p.Conflict
+	w.Rabbit = p.Rabbit
+	w.Fox = p.Fox
+	return nil
+}
+
+// Tworzy pusty świat (bez zwierząt) o podanych parametrach
+func NewWorldFromParams(p Params) *World {
+	w := NewWorld(p.Width, p.Height, p.MaxGrass, p.GrowthRate, p.Seed)
+	w.Conflict = p.Conflict
+	w.Topology = p.Topology
+	w.Neighborhood = p.Neighborhood
+	w.Obstacles = p.Obstacles
+	w.Rabbit = p.Rabbit
+	w.Fox = p.Fox
+	return w
+}
+
+// Parametry świata; Rabbits i Foxes to bieżące liczebności
+func (w *World) Params() Params {
+	animals := CountAnimals(w)
+	return Params{
+		Width:        w.Width,
+		Height:       w.Height,
+		Rabbits:      animals[Rabbit],
+		Foxes:        animals[Fox],
+		GrowthRate:   w.GrowthRate,
+		MaxGrass:     w.MaxGrass,
+		Seed:         w.Seed,
+		Conflict:     w.Conflict,
+		Topology:     w.Topology,
+		Neighborhood: w.Neighborhood,
+		Obstacles:    w.Obstacles,
+		Rabbit:       w.Rabbit,
+		Fox:          w.Fox,
+	}
+}
+
+// Parametry gatunku zwierzęcia
+func (w *World) speciesParams(animal int) *SpeciesParams {
+	if animal == Rabbit {
+		return &w.Rabbit
+	}
+	return &w.Fox
+}
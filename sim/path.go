@@ -0,0 +1,89 @@
+package sim
+
+import "math"
+
+// Pole osiągnięte przez przeszukiwanie wszerz
+type pathNode struct {
+	pos   [2]int
+	first [2]int // pierwszy krok drogi z pola startowego
+	depth int    // długość drogi w krokach
+}
+
+// Przeszukuje wszerz planszę wokół pos na głębokość radius, idąc tylko przez
+// wolne pola (bez zwierząt i przeszkód). Zwraca osiągnięte pola w kolejności
+// rosnącej odległości; zajęte pola też są zwracane (np. królik jako cel lisa),
+// ale droga przez nie nie prowadzi.
+func (w *World) explore(pos [2]int, radius int) []pathNode {
+	visited := map[[2]int]bool{pos: true}
+	var nodes []pathNode
+	queue := []pathNode{{pos: pos}}
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+		for _, n := range w.neighbors(cur.pos[0], cur.pos[1]) {
+			if visited[n] {
+				continue
+			}
+			visited[n] = true
+			node := pathNode{pos: n, first: cur.first, depth: cur.depth + 1}
+			if cur.depth == 0 {
+				node.first = n
+			}
+			nodes = append(nodes, node)
+			if w.free(n) && node.depth < radius {
+				queue = append(queue, node)
+			}
+		}
+	}
+	return nodes
+}
+
+// Pierwszy krok najkrótszej drogi do najbliższego pola spełniającego goal,
+// dalszego niż sąsiednie (sąsiednie cele obsługują reguły jedzenia i rozmnażania)
+func (w *World) pathStep(pos [2]int, radius int, goal func(n [2]int) bool) ([2]int, bool) {
+	for _, node := range w.explore(pos, radius) {
+		if goal(node.pos) {
+			return node.first, node.depth > 1
+		}
+	}
+	return [2]int{}, false
+}
+
+// Ruch o krok w stronę celu po drodze znalezionej przez pathStep
+func (w *World) stepIntent(sp *species, pos [2]int, cell Cell, goal func(n [2]int) bool) (intent, bool) {
+	step, ok := w.pathStep(pos, sp.Vision, goal)
+	if !ok {
+		return intent{}, false
+	}
+	return w.moveIntent(pos, step, cell, w.Grid[step[1]][step[0]].Ground), true
+}
+
+// Ucieczka po drodze: spośród pól osiągalnych w zasięgu wzroku wybiera to,
+// które jest najdalej od najbliższego drapieżnika (przy remisie – bliższe),
+// i robi pierwszy krok w jego stronę. W odróżnieniu od ucieczki na sąsiednie
+// pole nie zapędza zwierzęcia w ślepe zaułki między przeszkodami.
+func (w *World) fleePath(sp *species, pos [2]int, cell Cell, predators [][2]int) (intent, bool) {
+	best := -1.0
+	var step [2]int
+	for _, node := range w.explore(pos, sp.Vision) {
+		if !w.free(node.pos) {
+			continue
+		}
+		minDist := math.Inf(1)
+		for _, f := range predators {
+			minDist = min(minDist, w.dist2(f, node.pos))
+		}
+		if minDist > best {
+			best, step = minDist, node.first
+		}
+	}
+	if best < 0 {
+		return intent{}, false
+	}
+	return w.moveIntent(pos, step, cell, w.Grid[step[1]][step[0]].Ground), true
+}
+
+// Czy zwierzę na polu może się teraz rozmnażać
+func canMate(c Cell, sp *species) bool {
+	return c.Animal == sp.animal && c.Energy >= sp.ReproduceEnergy && c.ReproduceCooldown == 0
+}
@@ -0,0 +1,118 @@
+package sim
+
+import (
+	"slices"
+	"testing"
+)
+
+// Pusta plansza bez trawy z przeszkodami na podanych polach
+func pathWorld(width, height int, topology Topology, neighborhood Neighborhood, obstacles ...[2]int) *World {
+	w := NewWorld(width, height, GrassTall, 0, 1)
+	w.Topology = topology
+	w.Neighborhood = neighborhood
+	for _, o := range obstacles {
+		w.Grid[o[1]][o[0]].Ground = Obstacle
+	}
+	return w
+}
+
+// Pole celu w wyniku explore; false, gdy przeszukiwanie go nie osiągnęło
+func findNode(nodes []pathNode, pos [2]int) (pathNode, bool) {
+	for _, n := range nodes {
+		if n.pos == pos {
+			return n, true
+		}
+	}
+	return pathNode{}, false
+}
+
+// Ściana przeszkód w kolumnie 3 z przejściem tylko w dolnym wierszu
+var columnWall = [][2]int{{3, 0}, {3, 1}, {3, 2}, {3, 3}}
+
+func TestExploreGoesAroundObstacles(t *testing.T) {
+	open := pathWorld(7, 5, TopologyBounded, NeighborhoodMoore)
+	if n, ok := findNode(open.explore([2]int{1, 1}, 10), [2]int{5, 1}); !ok || n.depth != 4 {
+		t.Fatalf("bez przeszkód: %+v, %v; oczekiwano drogi długości 4", n, ok)
+	}
+
+	w := pathWorld(7, 5, TopologyBounded, NeighborhoodMoore, columnWall...)
+	n, ok := findNode(w.explore([2]int{1, 1}, 10), [2]int{5, 1})
+	if !ok || n.depth != 6 {
+		t.Fatalf("ze ścianą: %+v, %v; oczekiwano drogi długości 6 przez (3, 4)", n, ok)
+	}
+	// Tylko z tych pól przejście (3, 4) jest osiągalne w dwóch krokach
+	if !slices.Contains([][2]int{{1, 2}, {2, 2}}, n.first) {
+		t.Errorf("pierwszy krok %v nie prowadzi najkrótszą drogą", n.first)
+	}
+}
+
+func TestPathStepRespectsVisionRadius(t *testing.T) {
+	w := pathWorld(7, 5, TopologyBounded, NeighborhoodMoore, columnWall...)
+	goal := func(n [2]int) bool { return n == [2]int{5, 1} }
+	if _, ok := w.pathStep([2]int{1, 1}, 5, goal); ok {
+		t.Error("cel 6 kroków dalej znaleziony przy zasięgu 5")
+	}
+	if _, ok := w.pathStep([2]int{1, 1}, 6, goal); !ok {
+		t.Error("cel 6 kroków dalej nie znaleziony przy zasięgu 6")
+	}
+}
+
+func TestPathStepWrapsAroundTorus(t *testing.T) {
+	w := pathWorld(8, 3, TopologyTorus, NeighborhoodVonNeumann)
+	step, ok := w.pathStep([2]int{0, 1}, 3, func(n [2]int) bool { return n == [2]int{6, 1} })
+	if !ok || step != [2]int{7, 1} {
+		t.Fatalf("krok %v, %v; oczekiwano (7, 1) przez lewą krawędź", step, ok)
+	}
+
+	bounded := pathWorld(8, 3, TopologyBounded, NeighborhoodVonNeumann)
+	if _, ok := bounded.pathStep([2]int{0, 1}, 3, func(n [2]int) bool { return n == [2]int{6, 1} }); ok {
+		t.Error("na planszy ograniczonej cel 6 kroków dalej znaleziony przy zasięgu 3")
+	}
+}
+
+func TestPathStepUnreachableGoal(t *testing.T) {
+	// Cel (5, 2) otoczony ze wszystkich stron przeszkodami
+	var ring [][2]int
+	for dx := -1; dx <= 1; dx++ {
+		for dy := -1; dy <= 1; dy++ {
+			if dx != 0 || dy != 0 {
+				ring = append(ring, [2]int{5 + dx, 2 + dy})
+			}
+		}
+	}
+	w := pathWorld(7, 5, TopologyBounded, NeighborhoodMoore, ring...)
+	if step, ok := w.pathStep([2]int{1, 2}, 20, func(n [2]int) bool { return n == [2]int{5, 2} }); ok {
+		t.Errorf("znaleziono drogę do niedostępnego celu, krok %v", step)
+	}
+
+	// Sąsiedni cel obsługują reguły jedzenia i rozmnażania, nie szukanie drogi
+	if _, ok := w.pathStep([2]int{1, 2}, 20, func(n [2]int) bool { return n == [2]int{2, 2} }); ok {
+		t.Error("szukanie drogi do sąsiedniego pola")
+	}
+}
+
+// Ucieczka po drodze omija ślepy zaułek, w który zapędza zwykła ucieczka na
+// najdalsze sąsiednie pole
+func TestFleePathAvoidsDeadEnd(t *testing.T) {
+	// Zaułek (3, 2) zamknięty przeszkodami; lis na (1, 2), królik na (2, 2)
+	w := pathWorld(7, 5, TopologyBounded, NeighborhoodMoore, [2]int{3, 1}, [2]int{3, 3}, [2]int{4, 1}, [2]int{4, 2}, [2]int{4, 3})
+	w.Grid[2][1] = Cell{Animal: Fox, Energy: 10}
+	w.Grid[2][2] = Cell{Animal: Rabbit, Energy: 10}
+	w.Rabbit.Vision = 4
+
+	sp := rabbitSpecies
+	sp.SpeciesParams = &w.Rabbit
+	pos := [2]int{2, 2}
+	ns := w.neighbors(pos[0], pos[1])
+
+	in, ok := ruleFlee(w, &sp, pos, w.Grid[2][2], ns)
+	if !ok || in.to != [2]int{3, 2} {
+		t.Fatalf("zwykła ucieczka: %v, %v; oczekiwano wejścia w zaułek (3, 2)", in.to, ok)
+	}
+
+	w.Rabbit.Pathfinding = true
+	in, ok = ruleFlee(w, &sp, pos, w.Grid[2][2], ns)
+	if !ok || !slices.Contains([][2]int{{2, 1}, {2, 3}}, in.to) {
+		t.Fatalf("ucieczka po drodze: %v, %v; oczekiwano obejścia zaułka przez (2, 1) lub (2, 3)", in.to, ok)
+	}
+}
@@ -0,0 +1,7 @@
+package sim
+
+// Renderer rysuje bieżący stan świata. Implementacje (np. graficzna w raylib)
+// znajdują się poza pakietem sim, aby model nie zależał od bibliotek graficznych.
+type Renderer interface {
+	Draw(w *World)
+}
@@ -0,0 +1,139 @@
+package sim
+
+func (w *World) GrowGrass() {
+	for y := 0; y < w.Height; y++ {
+		for x := 0; x < w.Width; x++ {
+			if w.Grid[y][x].Ground == Obstacle {
+				continue
+			}
+			if w.Grid[y][x].Ground == Empty {
+				hasGrassNeighbor := false
+				for _, n := range w.neighbors(x, y) {
+					if isGrass(w.Grid[n[1]][n[0]].Ground) {
+						hasGrassNeighbor = true
+						break
+					}
+				}
+				if hasGrassNeighbor && w.rng.Float64() < w.GrowthRate {
+					w.Grid[y][x].Ground = GrassShort
+				}
+			} else if w.Grid[y][x].Ground >= w.maxGrassStage() {
+				// Trawa osiągnęła najwyższe dozwolone stadium
+			} else if w.Grid[y][x].Ground == GrassShort {
+				if w.rng.Float64() < w.GrowthRate {
+					w.Grid[y][x].Ground = GrassMedium
+				}
+			} else if w.Grid[y][x].Ground == GrassMedium {
+				if w.rng.Float64() < w.GrowthRate {
+					w.Grid[y][x].Ground = GrassTall
+				}
+			}
+		}
+	}
+}
+
+// Ruch królików: ucieczka, jedzenie trawy, rozmnażanie albo ruch losowy
+func (w *World) MoveRabbits() {
+	w.moveSpecies(&rabbitSpecies)
+}
+
+// Ruch lisów: polowanie, rozmnażanie albo ruch losowy
+func (w *World) MoveFoxes() {
+	w.moveSpecies(&foxSpecies)
+}
+
+func (w *World) UpdateEnergy() {
+	for y := 0; y < w.Height; y++ {
+		for x := 0; x < w.Width; x++ {
+			if w.Grid[y][x].Animal == Rabbit || w.Grid[y][x].Animal == Fox {
+				sp := w.speciesParams(w.Grid[y][x].Animal)
+				// Zużycie energii rośnie z wiekiem
+				energyLoss := sp.BaseEnergyLoss + float64(w.Grid[y][x].Age)*sp.AgeEnergyLoss
+				w.Grid[y][x].Energy -= energyLoss
+				// Wiek liczy przeżyte tury (do zużycia energii, MaxAge i statystyk)
+				w.Grid[y][x].Age++
+				if w.Grid[y][x].ReproduceCooldown > 0 {
+					w.Grid[y][x].ReproduceCooldown--
+				}
+				cause := CauseNone
+				if w.Grid[y][x].Energy <= 0 {
+					cause = CauseStarvation
+				} else if sp.MaxAge > 0 && w.Grid[y][x].Age >= sp.MaxAge {
+					cause = CauseOldAge
+				}
+				if cause != CauseNone {
+					if w.Grid[y][x].Animal == Rabbit {
+						w.stats.RabbitDeaths++
+					} else {
+						w.stats.FoxDeaths++
+					}
+					w.recordDeath(w.Grid[y][x].ID, cause, 0)
+					w.emit(Event{Kind: EventDeath, Animal: w.Grid[y][x].ID, Species: w.Grid[y][x].Animal,
+						From: [2]int{x, y}, Pos: [2]int{x, y}, Cause: cause})
+					w.Grid[y][x].ID = 0
+					w.Grid[y][x].Animal = Empty
+					w.Grid[y][x].Energy = 0
+					w.Grid[y][x].ReproduceCooldown = 0
+					w.Grid[y][x].Age = 0
+				}
+			}
+		}
+	}
+}
+
+// Jedna tura symulacji: wzrost trawy, ruch zwierząt i zużycie energii.
+// Statystyki po turze są dopisywane do History i zwracane; obejmują też
+// zmiany wprowadzone między turami (PlaceAnimal, RemoveAnimal).
+func (w *World) Step() Population {
+	w.GrowGrass()
+	w.MoveRabbits()
+	w.MoveFoxes()
+	w.UpdateEnergy()
+
+	w.Turn++
+	pop := w.Census()
+	w.History = append(w.History, pop)
+	w.stats = Population{}
+	return pop
+}
+
+// Statystyki bieżącego stanu: liczebności, trawa oraz średnia energia i wiek;
+// narodziny i zgony to zmiany wprowadzone od ostatniej tury
+func (w *World) Census() Population {
+	pop := w.stats
+	pop.Turn = w.Turn
+	var rabbitEnergy, foxEnergy float64
+	var rabbitAge, foxAge int
+	for y := 0; y < w.Height; y++ {
+		for x := 0; x < w.Width; x++ {
+			c := w.Grid[y][x]
+			switch c.Ground {
+			case GrassShort:
+				pop.GrassShort++
+			case GrassMedium:
+				pop.GrassMedium++
+			case GrassTall:
+				pop.GrassTall++
+			}
+			switch c.Animal {
+			case Rabbit:
+				pop.Rabbits++
+				rabbitEnergy += c.Energy
+				rabbitAge += c.Age
+			case Fox:
+				pop.Foxes++
+				foxEnergy += c.Energy
+				foxAge += c.Age
+			}
+		}
+	}
+	if pop.Rabbits > 0 {
+		pop.RabbitAvgEnergy = rabbitEnergy / float64(pop.Rabbits)
+		pop.RabbitAvgAge = float64(rabbitAge) / float64(pop.Rabbits)
+	}
+	if pop.Foxes > 0 {
+		pop.FoxAvgEnergy = foxEnergy / float64(pop.Foxes)
+		pop.FoxAvgAge = float64(foxAge) / float64(pop.Foxes)
+	}
+	return pop
+}
@@ -0,0 +1,266 @@
+package sim
+
+import (
+	"sync"
+	"time"
+)
+
+// Runner prowadzi symulację świata we własnej gorutynie (Run) z zadanym
+// tempem, z prawdziwą pauzą, wykonywaniem pojedynczych tur i przewijaniem
+// bez limitu tempa. Sterujące metody można wołać z dowolnej gorutyny; zmiany
+// świata (Do, Load) wykonywane są między turami, więc nie wymagają blokad
+// po stronie wołającego. Kolejne stany świata (kopie) trafiają do Updates.
+type Runner struct {
+	// Wywoływane w gorutynie Run po każdej turze i po podmianie świata
+	OnStep func(w *World, pop Population)
+	OnLoad func(w *World)
+
+	mu     sync.Mutex
+	paused bool
+	fast   bool
+	tps    float64
+	steps  int      // tury do wykonania w czasie pauzy
+	edits  []func() // zmiany czekające na wykonanie między turami
+	reads  []func() // odczyty czekające na wykonanie między turami
+
+	wake    chan struct{}
+	updates chan *World
+	done    chan struct{}
+
+	w *World // używany tylko w gorutynie Run
+}
+
+// Stan sterowania symulacją
+type RunnerState struct {
+	Paused      bool
+	FastForward bool
+	TPS         float64 // tury na sekundę (pomijane przy przewijaniu)
+}
+
+// Najmniejsze i największe tempo ustawiane przez SetTPS
+const (
+	MinTPS = 0.5
+	MaxTPS = 120
+)
+
+// Co ile przy przewijaniu wysyłany jest stan świata; tury pomiędzy nie są rysowane
+const fastForwardPublish = time.Second / 30
+
+// Tworzy sterownik dla świata w; symulacja rusza dopiero po wywołaniu Run
+func NewRunner(w *World, tps float64) *Runner {
+	return &Runner{
+		tps:     clampTPS(tps),
+		wake:    make(chan struct{}, 1),
+		updates: make(chan *World, 1),
+		done:    make(chan struct{}),
+		w:       w,
+	}
+}
+
+func clampTPS(tps float64) float64 {
+	return max(MinTPS, min(tps, MaxTPS))
+}
+
+// Kanał z kopiami świata po turach i zmianach. Zawiera tylko najnowszy stan –
+// stan nieodebrany przed następnym jest zastępowany. Zamykany, gdy Run się kończy.
+func (r *Runner) Updates() <-chan *World {
+	return r.updates
+}
+
+// Prowadzi symulację do zamknięcia stop albo wyginięcia wszystkich zwierząt;
+// ostatni stan świata trafia do Updates przed zamknięciem kanału
+func (r *Runner) Run(stop <-chan struct{}) {
+	defer close(r.updates)
+	defer close(r.done)
+
+	r.publish()
+	next := time.Now()
+	lastPublish := time.Now()
+	var timer *time.Timer
+	for {
+		r.applyEdits()
+
+		r.mu.Lock()
+		paused, fast, steps, interval := r.paused, r.fast, r.steps, time.Duration(float64(time.Second)/r.tps)
+		r.mu.Unlock()
+
+		// Czekanie na zmianę sterowania albo na czas kolejnej tury
+		var due <-chan time.Time
+		if !paused && !fast {
+			if d := time.Until(next); d > 0 {
+				if timer == nil {
+					timer = time.NewTimer(d)
+				} else {
+					timer.Reset(d)
+				}
+				due = timer.C
+			}
+		}
+		if paused && steps == 0 || due != nil {
+			select {
+			case <-stop:
+				r.publish()
+				return
+			case <-r.wake:
+				if timer != nil {
+					timer.Stop()
+				}
+				continue
+			case <-due:
+			}
+		} else {
+			select {
+			case <-stop:
+				r.publish()
+				return
+			default:
+			}
+		}
+
+		if paused {
+			r.mu.Lock()
+			r.steps = max(0, r.steps-1)
+			r.mu.Unlock()
+		}
+		pop := r.w.Step()
+		if r.OnStep != nil {
+			r.OnStep(r.w, pop)
+		}
+		next = time.Now().Add(interval)
+		extinct := pop.Rabbits+pop.Foxes == 0
+		if extinct || !fast || paused || time.Since(lastPublish) >= fastForwardPublish {
+			r.publish()
+			lastPublish = time.Now()
+		}
+		if extinct {
+			return
+		}
+	}
+}
+
+// Wysyła kopię świata, zastępując stan jeszcze nieodebrany
+func (r *Runner) publish() {
+	select {
+	case <-r.updates:
+	default:
+	}
+	r.updates <- r.w.Copy()
+}
+
+// Wykonuje zmiany zlecone przez Do i Load, a po nich odczyty z View
+func (r *Runner) applyEdits() {
+	r.mu.Lock()
+	edits, reads := r.edits, r.reads
+	r.edits, r.reads = nil, nil
+	r.mu.Unlock()
+	for _, edit := range edits {
+		edit()
+	}
+	if len(edits) > 0 {
+		r.publish()
+	}
+	for _, read := range reads {
+		read()
+	}
+}
+
+// Zleca zmianę sterowania i budzi gorutynę Run
+func (r *Runner) control(change func()) {
+	r.mu.Lock()
+	change()
+	r.mu.Unlock()
+	select {
+	case r.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (r *Runner) State() RunnerState {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return RunnerState{Paused: r.paused, FastForward: r.fast, TPS: r.tps}
+}
+
+func (r *Runner) SetPaused(paused bool) {
+	r.control(func() { r.paused, r.steps = paused, 0 })
+}
+
+func (r *Runner) TogglePause() {
+	r.control(func() { r.paused, r.steps = !r.paused, 0 })
+}
+
+// Wykonuje n tur w czasie pauzy (bez pauzy nie ma znaczenia)
+func (r *Runner) Step(n int) {
+	r.control(func() {
+		if r.paused {
+			r.steps += n
+		}
+	})
+}
+
+// Ustawia tempo w turach na sekundę (ograniczone do MinTPS–MaxTPS)
+func (r *Runner) SetTPS(tps float64) {
+	r.control(func() { r.tps = clampTPS(tps) })
+}
+
+// Włącza przewijanie: tury bez limitu tempa, stan wysyłany najwyżej 30 razy na sekundę
+func (r *Runner) SetFastForward(fast bool) {
+	r.control(func() { r.fast = fast })
+}
+
+// Wykonuje f na świecie między turami i czeka na jej zakończenie. Zwraca
+// false, gdy symulacja już się zakończyła i f nie została wykonana.
+func (r *Runner) Do(f func(w *World)) bool {
+	applied := make(chan struct{})
+	r.control(func() {
+		r.edits = append(r.edits, func() {
+			f(r.w)
+			close(applied)
+		})
+	})
+	return r.wait(applied)
+}
+
+// Wykonuje f na świecie między turami i czeka na jej zakończenie, jak Do,
+// ale bez wysyłania stanu do Updates – f nie może zmieniać świata. Zwraca
+// false, gdy symulacja już się zakończyła.
+func (r *Runner) View(f func(w *World)) bool {
+	applied := make(chan struct{})
+	r.control(func() {
+		r.reads = append(r.reads, func() {
+			f(r.w)
+			close(applied)
+		})
+	})
+	return r.wait(applied)
+}
+
+// Podmienia symulowany świat (np. na wczytany ze zrzutu) między turami
+func (r *Runner) Load(w *World) bool {
+	applied := make(chan struct{})
+	r.control(func() {
+		r.edits = append(r.edits, func() {
+			r.w = w
+			if r.OnLoad != nil {
+				r.OnLoad(w)
+			}
+			close(applied)
+		})
+	})
+	return r.wait(applied)
+}
+
+// Czeka na wykonanie zmiany albo zakończenie Run
+func (r *Runner) wait(applied chan struct{}) bool {
+	select {
+	case <-applied:
+		return true
+	case <-r.done:
+		select {
+		case <-applied:
+			return true
+		default:
+			return false
+		}
+	}
+}
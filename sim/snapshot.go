@@ -0,0 +1,451 @@
+package sim
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+)
+
+// Wersja formatu zrzutu; zwiększana przy każdej niezgodnej zmianie
+const SnapshotVersion = 1
+
+// Największy bok planszy w zrzucie binarnym; chroni przed ogromnymi
+// alokacjami przy uszkodzonym lub spreparowanym pliku
+const maxSnapshotSide = 1 << 16
+
+// Nagłówek binarnego formatu zrzutu
+var snapshotMagic = [4]byte{'K', 'i', 'L', 'S'}
+
+// Snapshot to pełny stan świata: plansza, parametry, numer tury,
+// stan generatora liczb losowych, historia populacji i metryki zwierząt.
+type Snapshot struct {
+	Version    int            `json:"version"`
+	Width      int            `json:"width"`
+	Height     int            `json:"height"`
+	MaxGrass   int            `json:"maxGrass"`
+	GrowthRate float64        `json:"growthRate"`
+	Seed       uint64         `json:"seed"`
+	Turn       int            `json:"turn"`
+	RNG        []byte         `json:"rng"`
+	Params     *Params        `json:"params"`
+	Grid       [][]Cell       `json:"grid"`
+	History    []Population   `json:"history"`
+	Pending    Population     `json:"pending"` // narodziny i zgony ze zmian między turami, wliczane do następnej tury
+	Animals    []AnimalRecord `json:"animals"`
+}
+
+// Tworzy zrzut bieżącego stanu świata
+func (w *World) Snapshot() Snapshot {
+	state, _ := w.pcg.MarshalBinary() // PCG nigdy nie zwraca błędu
+	c := w.Copy()
+	params := c.Params()
+	return Snapshot{
+		Version:    SnapshotVersion,
+		Width:      c.Width,
+		Height:     c.Height,
+		MaxGrass:   c.MaxGrass,
+		GrowthRate: c.GrowthRate,
+		Seed:       c.Seed,
+		Turn:       c.Turn,
+		RNG:        state,
+		Params:     &params,
+		Grid:       c.Grid,
+		History:    append([]Population(nil), c.History...),
+		Pending:    c.stats,
+		Animals:    c.animals,
+	}
+}
+
+// Odtwarza świat ze zrzutu
+func FromSnapshot(s Snapshot) (*World, error) {
+	if s.Version != SnapshotVersion {
+		return nil, fmt.Errorf("nieobsługiwana wersja zrzutu %d (obsługiwana %d)", s.Version, SnapshotVersion)
+	}
+	if s.Width <= 0 || s.Height <= 0 {
+		return nil, fmt.Errorf("nieprawidłowy rozmiar planszy %dx%d", s.Width, s.Height)
+	}
+	if len(s.Grid) != s.Height {
+		return nil, fmt.Errorf("plansza ma %d wierszy, oczekiwano %d", len(s.Grid), s.Height)
+	}
+	// Długości wierszy sprawdzane przed utworzeniem świata, aby podana
+	// szerokość nie wymusiła alokacji większej niż sam zrzut
+	for y, row := range s.Grid {
+		if len(row) != s.Width {
+			return nil, fmt.Errorf("wiersz %d ma %d pól, oczekiwano %d", y, len(row), s.Width)
+		}
+	}
+	if s.Params == nil {
+		return nil, errors.New("zrzut nie zawiera parametrów")
+	}
+	p := *s.Params
+	if err := p.Validate(); err != nil {
+		return nil, fmt.Errorf("parametry zrzutu: %w", err)
+	}
+	if s.Turn < 0 {
+		return nil, fmt.Errorf("nieprawidłowy numer tury %d", s.Turn)
+	}
+	if err := checkSnapshotContents(s); err != nil {
+		return nil, err
+	}
+
+	w := NewWorld(s.Width, s.Height, s.MaxGrass, s.GrowthRate, s.Seed)
+	w.Conflict = p.Conflict
+	w.Topology = p.Topology
+	w.Neighborhood = p.Neighborhood
+	w.Obstacles = p.Obstacles
+	w.Rabbit = p.Rabbit
+	w.Fox = p.Fox
+	for y, row := range s.Grid {
+		copy(w.Grid[y], row)
+	}
+	if err := w.pcg.UnmarshalBinary(s.RNG); err != nil {
+		return nil, fmt.Errorf("stan generatora: %w", err)
+	}
+	w.Turn = s.Turn
+	w.animals = append([]AnimalRecord(nil), s.Animals...)
+	w.History = append([]Population(nil), s.History...)
+	w.stats = Population{
+		RabbitBirths: s.Pending.RabbitBirths,
+		FoxBirths:    s.Pending.FoxBirths,
+		RabbitDeaths: s.Pending.RabbitDeaths,
+		FoxDeaths:    s.Pending.FoxDeaths,
+	}
+	return w, nil
+}
+
+// Sprawdza zawartość planszy i metryk zrzutu: zakresy podłoża, gatunków
+// i przyczyn śmierci oraz to, że każde żyjące zwierzę z metryk stoi na
+// planszy dokładnie raz, a każde zwierzę na planszy ma metrykę
+func checkSnapshotContents(s Snapshot) error {
+	n := uint64(len(s.Animals))
+	for i, r := range s.Animals {
+		id := uint64(i) + 1
+		_, causeErr := r.Cause.MarshalText()
+		switch {
+		case r.ID != id:
+			return fmt.Errorf("metryka zwierzęcia nr %d ma identyfikator %d", id, r.ID)
+		case r.Species != Rabbit && r.Species != Fox:
+			return fmt.Errorf("zwierzę %d: nieznany gatunek %d", id, r.Species)
+		case causeErr != nil:
+			return fmt.Errorf("zwierzę %d: %w", id, causeErr)
+		case r.Parents[0] >= id || r.Parents[1] >= id || r.KilledBy > n:
+			// Rodzice mają mniejsze identyfikatory niż młode (Children na tym polega)
+			return fmt.Errorf("zwierzę %d: odwołanie do nieistniejącego zwierzęcia", id)
+		}
+	}
+
+	onGrid := make([]bool, n)
+	for y, row := range s.Grid {
+		for x, c := range row {
+			if !isGrass(c.Ground) && c.Ground != Empty && c.Ground != Obstacle {
+				return fmt.Errorf("pole (%d, %d): nieznane podłoże %d", x, y, c.Ground)
+			}
+			if c.Animal == Empty {
+				if c.ID != 0 {
+					return fmt.Errorf("pole (%d, %d): identyfikator %d bez zwierzęcia", x, y, c.ID)
+				}
+				continue
+			}
+			switch {
+			case c.Animal != Rabbit && c.Animal != Fox:
+				return fmt.Errorf("pole (%d, %d): nieznane zwierzę %d", x, y, c.Animal)
+			case c.Ground == Obstacle:
+				return fmt.Errorf("pole (%d, %d): zwierzę na przeszkodzie", x, y)
+			case c.ID == 0 || c.ID > n:
+				return fmt.Errorf("pole (%d, %d): zwierzę %d nie ma metryki", x, y, c.ID)
+			case !s.Animals[c.ID-1].Alive() || s.Animals[c.ID-1].Species != c.Animal:
+				return fmt.Errorf("pole (%d, %d): zwierzę %d nie zgadza się ze swoją metryką", x, y, c.ID)
+			case onGrid[c.ID-1]:
+				return fmt.Errorf("zwierzę %d jest na planszy więcej niż raz", c.ID)
+			case math.IsNaN(c.Energy) || c.ReproduceCooldown < 0 || c.Age < 0:
+				return fmt.Errorf("pole (%d, %d): nieprawidłowy stan zwierzęcia %d", x, y, c.ID)
+			}
+			onGrid[c.ID-1] = true
+		}
+	}
+	for i, r := range s.Animals {
+		if r.Alive() && !onGrid[i] {
+			return fmt.Errorf("żyjącego zwierzęcia %d nie ma na planszy", r.ID)
+		}
+	}
+
+	pending := s.Pending
+	if pending.RabbitBirths < 0 || pending.FoxBirths < 0 || pending.RabbitDeaths < 0 || pending.FoxDeaths < 0 {
+		return errors.New("ujemne liczby narodzin lub zgonów między turami")
+	}
+	return nil
+}
+
+// Zapisuje zrzut do pliku; rozszerzenie .json wybiera format JSON,
+// każde inne – zwarty format binarny
+func SaveSnapshot(path string, w *World) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	bw := bufio.NewWriter(f)
+	if filepath.Ext(path) == ".json" {
+		err = WriteSnapshotJSON(bw, w.Snapshot())
+	} else {
+		err = WriteSnapshotBinary(bw, w.Snapshot())
+	}
+	if err != nil {
+		return err
+	}
+	if err := bw.Flush(); err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+// Wczytuje zrzut z pliku; format rozpoznawany jest po zawartości
+func LoadSnapshot(path string) (*World, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	s, err := ReadSnapshot(bufio.NewReader(f))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return FromSnapshot(s)
+}
+
+func WriteSnapshotJSON(out io.Writer, s Snapshot) error {
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", " ")
+	return enc.Encode(s)
+}
+
+// Format binarny: nagłówek "KiLS", wersja, parametry świata, stan generatora,
+// pola planszy, historia, zmiany między turami i metryki zwierząt. Liczby
+// całkowite zapisywane są jako varint, a dla pól bez zwierzęcia zapisywany
+// jest tylko typ podłoża.
+func WriteSnapshotBinary(out io.Writer, s Snapshot) error {
+	var buf []byte
+	buf = append(buf, snapshotMagic[:]...)
+	buf = binary.AppendUvarint(buf, uint64(s.Version))
+	buf = binary.AppendUvarint(buf, uint64(s.Width))
+	buf = binary.AppendUvarint(buf, uint64(s.Height))
+	buf = binary.AppendVarint(buf, int64(s.MaxGrass))
+	buf = binary.LittleEndian.AppendUint64(buf, math.Float64bits(s.GrowthRate))
+	buf = binary.LittleEndian.AppendUint64(buf, s.Seed)
+	buf = binary.AppendUvarint(buf, uint64(s.Turn))
+	buf = binary.AppendUvarint(buf, uint64(len(s.RNG)))
+	buf = append(buf, s.RNG...)
+	// Parametry jako JSON: rzadko się zmieniają, a tak łatwiej dodawać nowe pola
+	params, err := json.Marshal(s.Params)
+	if err != nil {
+		return err
+	}
+	buf = binary.AppendUvarint(buf, uint64(len(params)))
+	buf = append(buf, params...)
+	for _, row := range s.Grid {
+		for _, c := range row {
+			buf = append(buf, byte(c.Ground), byte(c.Animal))
+			if c.Animal == Empty {
+				continue
+			}
+			buf = binary.AppendUvarint(buf, c.ID)
+			buf = binary.LittleEndian.AppendUint64(buf, math.Float64bits(c.Energy))
+			buf = binary.AppendVarint(buf, int64(c.ReproduceCooldown))
+			buf = binary.AppendVarint(buf, int64(c.Age))
+		}
+	}
+	buf = binary.AppendUvarint(buf, uint64(len(s.History)))
+	for _, p := range s.History {
+		for _, v := range []int{p.Turn, p.Rabbits, p.Foxes, p.GrassShort, p.GrassMedium, p.GrassTall,
+			p.RabbitBirths, p.FoxBirths, p.RabbitDeaths, p.FoxDeaths} {
+			buf = binary.AppendUvarint(buf, uint64(v))
+		}
+		for _, v := range []float64{p.RabbitAvgEnergy, p.FoxAvgEnergy, p.RabbitAvgAge, p.FoxAvgAge} {
+			buf = binary.LittleEndian.AppendUint64(buf, math.Float64bits(v))
+		}
+	}
+	for _, v := range []int{s.Pending.RabbitBirths, s.Pending.FoxBirths, s.Pending.RabbitDeaths, s.Pending.FoxDeaths} {
+		buf = binary.AppendUvarint(buf, uint64(v))
+	}
+	buf = binary.AppendUvarint(buf, uint64(len(s.Animals)))
+	for _, r := range s.Animals {
+		buf = binary.AppendUvarint(buf, r.ID)
+		buf = append(buf, byte(r.Species), byte(r.Cause))
+		buf = binary.AppendUvarint(buf, uint64(r.BirthTurn))
+		buf = binary.AppendUvarint(buf, uint64(r.DeathTurn))
+		buf = binary.AppendUvarint(buf, r.Parents[0])
+		buf = binary.AppendUvarint(buf, r.Parents[1])
+		buf = binary.AppendUvarint(buf, r.KilledBy)
+	}
+	_, err = out.Write(buf)
+	return err
+}
+
+// Czyta zrzut w formacie JSON lub binarnym
+func ReadSnapshot(in io.Reader) (Snapshot, error) {
+	data, err := io.ReadAll(in)
+	if err != nil {
+		return Snapshot{}, err
+	}
+	if bytes.HasPrefix(data, snapshotMagic[:]) {
+		return decodeSnapshotBinary(data[len(snapshotMagic):])
+	}
+	var s Snapshot
+	if err := json.Unmarshal(data, &s); err != nil {
+		return Snapshot{}, fmt.Errorf("nieprawidłowy zrzut: %w", err)
+	}
+	return s, nil
+}
+
+var errSnapshotTruncated = errors.New("zrzut jest ucięty")
+
+// Pomocniczy czytnik formatu binarnego; pierwszy błąd zatrzymuje dalsze odczyty
+type snapshotDecoder struct {
+	data []byte
+	err  error
+}
+
+func (d *snapshotDecoder) uvarint() uint64 {
+	if d.err != nil {
+		return 0
+	}
+	v, n := binary.Uvarint(d.data)
+	if n <= 0 {
+		d.err = errSnapshotTruncated
+		return 0
+	}
+	d.data = d.data[n:]
+	return v
+}
+
+func (d *snapshotDecoder) varint() int64 {
+	if d.err != nil {
+		return 0
+	}
+	v, n := binary.Varint(d.data)
+	if n <= 0 {
+		d.err = errSnapshotTruncated
+		return 0
+	}
+	d.data = d.data[n:]
+	return v
+}
+
+func (d *snapshotDecoder) bytes(n int) []byte {
+	if d.err != nil {
+		return nil
+	}
+	if n < 0 || len(d.data) < n {
+		d.err = errSnapshotTruncated
+		return nil
+	}
+	b := d.data[:n]
+	d.data = d.data[n:]
+	return b
+}
+
+func (d *snapshotDecoder) uint64() uint64 {
+	b := d.bytes(8)
+	if b == nil {
+		return 0
+	}
+	return binary.LittleEndian.Uint64(b)
+}
+
+func decodeSnapshotBinary(data []byte) (Snapshot, error) {
+	d := &snapshotDecoder{data: data}
+	var s Snapshot
+	s.Version = int(d.uvarint())
+	if d.err == nil && s.Version != SnapshotVersion {
+		return Snapshot{}, fmt.Errorf("nieobsługiwana wersja zrzutu %d (obsługiwana %d)", s.Version, SnapshotVersion)
+	}
+	width, height := d.uvarint(), d.uvarint()
+	s.MaxGrass = int(d.varint())
+	s.GrowthRate = math.Float64frombits(d.uint64())
+	s.Seed = d.uint64()
+	s.Turn = int(d.uvarint())
+	s.RNG = append([]byte(nil), d.bytes(int(d.uvarint()))...)
+	if params := d.bytes(int(d.uvarint())); d.err == nil {
+		if err := json.Unmarshal(params, &s.Params); err != nil {
+			return Snapshot{}, fmt.Errorf("parametry zrzutu: %w", err)
+		}
+	}
+	if d.err != nil {
+		return Snapshot{}, d.err
+	}
+	// Boki sprawdzane osobno, zanim zostaną pomnożone, aby iloczyn nie przekroczył zakresu
+	if width == 0 || height == 0 || width > maxSnapshotSide || height > maxSnapshotSide ||
+		width*height > uint64(len(d.data)) {
+		return Snapshot{}, fmt.Errorf("nieprawidłowy rozmiar planszy %dx%d", width, height)
+	}
+	s.Width, s.Height = int(width), int(height)
+
+	s.Grid = make([][]Cell, s.Height)
+	for y := range s.Grid {
+		s.Grid[y] = make([]Cell, s.Width)
+		for x := range s.Grid[y] {
+			b := d.bytes(2)
+			if b == nil {
+				return Snapshot{}, d.err
+			}
+			c := Cell{Ground: int(b[0]), Animal: int(b[1])}
+			if c.Animal != Empty {
+				c.ID = d.uvarint()
+				c.Energy = math.Float64frombits(d.uint64())
+				c.ReproduceCooldown = int(d.varint())
+				c.Age = int(d.varint())
+			}
+			s.Grid[y][x] = c
+		}
+	}
+
+	n := d.uvarint()
+	if d.err == nil && n > uint64(len(d.data)) {
+		return Snapshot{}, errSnapshotTruncated
+	}
+	s.History = make([]Population, 0, n)
+	for i := uint64(0); i < n && d.err == nil; i++ {
+		var p Population
+		for _, v := range []*int{&p.Turn, &p.Rabbits, &p.Foxes, &p.GrassShort, &p.GrassMedium, &p.GrassTall,
+			&p.RabbitBirths, &p.FoxBirths, &p.RabbitDeaths, &p.FoxDeaths} {
+			*v = int(d.uvarint())
+		}
+		for _, v := range []*float64{&p.RabbitAvgEnergy, &p.FoxAvgEnergy, &p.RabbitAvgAge, &p.FoxAvgAge} {
+			*v = math.Float64frombits(d.uint64())
+		}
+		s.History = append(s.History, p)
+	}
+	for _, v := range []*int{&s.Pending.RabbitBirths, &s.Pending.FoxBirths, &s.Pending.RabbitDeaths, &s.Pending.FoxDeaths} {
+		*v = int(d.uvarint())
+	}
+
+	n = d.uvarint()
+	if d.err == nil && n > uint64(len(d.data)) {
+		return Snapshot{}, errSnapshotTruncated
+	}
+	s.Animals = make([]AnimalRecord, 0, n)
+	for i := uint64(0); i < n && d.err == nil; i++ {
+		r := AnimalRecord{ID: d.uvarint()}
+		if b := d.bytes(2); b != nil {
+			r.Species, r.Cause = int(b[0]), DeathCause(b[1])
+		}
+		r.BirthTurn = int(d.uvarint())
+		r.DeathTurn = int(d.uvarint())
+		r.Parents = [2]uint64{d.uvarint(), d.uvarint()}
+		r.KilledBy = d.uvarint()
+		s.Animals = append(s.Animals, r)
+	}
+	if d.err != nil {
+		return Snapshot{}, d.err
+	}
+	return s, nil
+}
@@ -0,0 +1,240 @@
+package sim_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"reflect"
+	"testing"
+
+	"example.com/mod/sim"
+)
+
+// Świat z rozmieszczonymi zwierzętami dla parametrów p
+func newTestWorld(t *testing.T, p sim.Params) *sim.World {
+	t.Helper()
+	if err := p.Validate(); err != nil {
+		t.Fatalf("nieprawidłowe parametry testu: %v", err)
+	}
+	w := sim.NewWorldFromParams(p)
+	w.Initialize(p.Rabbits, p.Foxes)
+	return w
+}
+
+func snapshotTestParams() map[string]sim.Params {
+	variant := sim.DefaultParams()
+	variant.Seed = 11
+	variant.Width, variant.Height = 24, 18
+	variant.Rabbits, variant.Foxes = 30, 8
+	variant.Topology = sim.TopologyTorus
+	variant.Neighborhood = sim.NeighborhoodHex
+	variant.Obstacles = 0.1
+	variant.Rabbit.MaxAge = 40
+	variant.Rabbit.Vision, variant.Fox.Vision = 2, 3
+	variant.Fox.Pathfinding = true
+
+	def := sim.DefaultParams()
+	def.Seed = 7
+	return map[string]sim.Params{"default": def, "variant": variant}
+}
+
+// Zrzut zapisany i wczytany w obu formatach daje świat, który dalej
+// rozwija się dokładnie tak samo jak oryginał
+func TestSnapshotRoundTripContinuesIdentically(t *testing.T) {
+	formats := map[string]func(io.Writer, sim.Snapshot) error{
+		"json":   sim.WriteSnapshotJSON,
+		"binary": sim.WriteSnapshotBinary,
+	}
+	for name, p := range snapshotTestParams() {
+		for format, write := range formats {
+			t.Run(name+"/"+format, func(t *testing.T) {
+				orig := newTestWorld(t, p)
+				for range 30 {
+					orig.Step()
+				}
+
+				var buf bytes.Buffer
+				if err := write(&buf, orig.Snapshot()); err != nil {
+					t.Fatalf("zapis: %v", err)
+				}
+				s, err := sim.ReadSnapshot(&buf)
+				if err != nil {
+					t.Fatalf("odczyt: %v", err)
+				}
+				loaded, err := sim.FromSnapshot(s)
+				if err != nil {
+					t.Fatalf("FromSnapshot: %v", err)
+				}
+				if !reflect.DeepEqual(loaded.Snapshot(), orig.Snapshot()) {
+					t.Fatalf("wczytany świat różni się od zapisanego")
+				}
+
+				for turn := 1; turn <= 100; turn++ {
+					want, got := orig.Step(), loaded.Step()
+					if got != want {
+						t.Fatalf("tura %d po wczytaniu: %+v, oczekiwano %+v", orig.Turn, got, want)
+					}
+				}
+				if !reflect.DeepEqual(loaded.Snapshot(), orig.Snapshot()) {
+					t.Fatalf("stan po 100 turach różni się od oryginału")
+				}
+			})
+		}
+	}
+}
+
+// Wymiary, których iloczyn przekracza zakres liczb, nie mogą przejść
+// sprawdzenia rozmiaru i wymusić ogromnej alokacji
+func TestSnapshotBinaryRejectsHugeDimensions(t *testing.T) {
+	for _, size := range [][2]uint64{
+		{1 << 33, 1 << 31}, // iloczyn 2^64 przepełnia się do 0
+		{1 << 63, 2},
+		{1<<16 + 1, 1}, // powyżej największego boku
+		{0, 5},
+	} {
+		data := []byte("KiLS")
+		data = binary.AppendUvarint(data, sim.SnapshotVersion)
+		data = binary.AppendUvarint(data, size[0])
+		data = binary.AppendUvarint(data, size[1])
+		data = binary.AppendVarint(data, 8)
+		data = append(data, make([]byte, 16)...) // tempo wzrostu i ziarno
+		data = binary.AppendUvarint(data, 0)     // tura
+		data = binary.AppendUvarint(data, 0)     // stan generatora
+		data = binary.AppendUvarint(data, 4)
+		data = append(data, "null"...) // parametry
+		data = append(data, make([]byte, 64)...)
+
+		if _, err := sim.ReadSnapshot(bytes.NewReader(data)); err == nil {
+			t.Errorf("plansza %dx%d: oczekiwano błędu", size[0], size[1])
+		}
+	}
+}
+
+// Szerokość w zrzucie JSON niezgodna z wierszami jest błędem, zanim
+// zostanie utworzona plansza
+func TestFromSnapshotRejectsMismatchedWidth(t *testing.T) {
+	w := newTestWorld(t, snapshotTestParams()["default"])
+	s := w.Snapshot()
+	s.Width = 1 << 40
+	if _, err := sim.FromSnapshot(s); err == nil {
+		t.Fatal("oczekiwano błędu dla szerokości niezgodnej z wierszami")
+	}
+}
+
+// Zmiany wprowadzone między turami (narodziny i zgony z pędzla lub API)
+// należą do następnej tury, więc zrzut zrobiony po nich musi je zachować
+func TestSnapshotKeepsEditsBetweenTurns(t *testing.T) {
+	for format, write := range map[string]func(io.Writer, sim.Snapshot) error{
+		"json":   sim.WriteSnapshotJSON,
+		"binary": sim.WriteSnapshotBinary,
+	} {
+		t.Run(format, func(t *testing.T) {
+			orig := newTestWorld(t, snapshotTestParams()["default"])
+			for range 10 {
+				orig.Step()
+			}
+			placed, removed := false, false
+			for y := range orig.Height {
+				for x := range orig.Width {
+					switch c := orig.Grid[y][x]; {
+					case !placed && c.Animal == sim.Empty && c.Ground != sim.Obstacle:
+						placed = orig.PlaceAnimal(x, y, sim.Fox)
+					case !removed && c.Animal == sim.Rabbit:
+						removed = orig.RemoveAnimal(x, y)
+					}
+				}
+			}
+			if !placed || !removed {
+				t.Fatal("nie udało się zmienić planszy")
+			}
+
+			var buf bytes.Buffer
+			if err := write(&buf, orig.Snapshot()); err != nil {
+				t.Fatalf("zapis: %v", err)
+			}
+			s, err := sim.ReadSnapshot(&buf)
+			if err != nil {
+				t.Fatalf("odczyt: %v", err)
+			}
+			loaded, err := sim.FromSnapshot(s)
+			if err != nil {
+				t.Fatalf("FromSnapshot: %v", err)
+			}
+			want, got := orig.Step(), loaded.Step()
+			if got != want {
+				t.Fatalf("tura po wczytaniu: %+v, oczekiwano %+v", got, want)
+			}
+			if got.FoxBirths == 0 || got.RabbitDeaths == 0 {
+				t.Fatalf("zmiany między turami nie weszły do statystyk: %+v", got)
+			}
+		})
+	}
+}
+
+// Zrzut z niespójną zawartością planszy lub metryk jest odrzucany przy
+// wczytywaniu, zamiast tworzyć świat, który zawiedzie później
+func TestFromSnapshotRejectsInvalidContents(t *testing.T) {
+	// Pozycja pierwszego zwierzęcia gatunku animal na planszy zrzutu
+	find := func(s sim.Snapshot, animal int) (int, int) {
+		for y, row := range s.Grid {
+			for x, c := range row {
+				if c.Animal == animal {
+					return x, y
+				}
+			}
+		}
+		t.Fatalf("brak zwierzęcia %d na planszy", animal)
+		return 0, 0
+	}
+	for name, corrupt := range map[string]func(s *sim.Snapshot){
+		"nieznane podłoże": func(s *sim.Snapshot) { s.Grid[0][0].Ground = 9 },
+		"nieznane zwierzę": func(s *sim.Snapshot) {
+			x, y := find(*s, sim.Rabbit)
+			s.Grid[y][x].Animal = 7
+		},
+		"zwierzę na przeszkodzie": func(s *sim.Snapshot) {
+			x, y := find(*s, sim.Rabbit)
+			s.Grid[y][x].Ground = sim.Obstacle
+		},
+		"zwierzę bez metryki": func(s *sim.Snapshot) {
+			x, y := find(*s, sim.Rabbit)
+			s.Grid[y][x].ID = uint64(len(s.Animals)) + 1
+		},
+		"powtórzony identyfikator": func(s *sim.Snapshot) {
+			rx, ry := find(*s, sim.Rabbit)
+			for x := range s.Grid[ry] {
+				if c := s.Grid[ry][x]; x != rx && c.Animal == sim.Empty && c.Ground != sim.Obstacle {
+					s.Grid[ry][x] = s.Grid[ry][rx]
+					return
+				}
+			}
+			t.Fatal("brak wolnego pola obok królika")
+		},
+		"żyjące zwierzę poza planszą": func(s *sim.Snapshot) {
+			x, y := find(*s, sim.Fox)
+			s.Grid[y][x] = sim.Cell{Ground: s.Grid[y][x].Ground}
+		},
+		"inny gatunek niż w metryce": func(s *sim.Snapshot) {
+			x, y := find(*s, sim.Fox)
+			s.Grid[y][x].Animal = sim.Rabbit
+		},
+		"nieznana przyczyna śmierci": func(s *sim.Snapshot) { s.Animals[0].Cause = 42 },
+		"identyfikator pod pustym polem": func(s *sim.Snapshot) {
+			for x, c := range s.Grid[0] {
+				if c.Animal == sim.Empty {
+					s.Grid[0][x].ID = 1
+					return
+				}
+			}
+		},
+		"brak parametrów": func(s *sim.Snapshot) { s.Params = nil },
+	} {
+		t.Run(name, func(t *testing.T) {
+			s := newTestWorld(t, snapshotTestParams()["default"]).Snapshot()
+			corrupt(&s)
+			if _, err := sim.FromSnapshot(s); err == nil {
+				t.Fatal("oczekiwano błędu")
+			}
+		})
+	}
+}
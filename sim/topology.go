@@ -0,0 +1,133 @@
+package sim
+
+import "fmt"
+
+// Topology określa, jak zachowują się krawędzie planszy
+type Topology int
+
+const (
+	TopologyBounded    Topology = iota // plansza ograniczona: pola przy krawędzi mają mniej sąsiadów
+	TopologyTorus                      // plansza zawinięta: lewa krawędź sąsiaduje z prawą, górna z dolną
+	TopologyReflective                 // krawędź odbija: sąsiedzi za krawędzią to lustrzane odbicia pól wewnątrz
+)
+
+var topologyNames = []string{"bounded", "torus", "reflective"}
+
+func (t Topology) String() string {
+	if t < 0 || int(t) >= len(topologyNames) {
+		return fmt.Sprintf("Topology(%d)", int(t))
+	}
+	return topologyNames[t]
+}
+
+// Rozpoznaje nazwę topologii: bounded, torus lub reflective
+func ParseTopology(name string) (Topology, error) {
+	for i, n := range topologyNames {
+		if n == name {
+			return Topology(i), nil
+		}
+	}
+	return 0, fmt.Errorf("nieznana topologia %q (dostępne: bounded, torus, reflective)", name)
+}
+
+func (t Topology) MarshalText() ([]byte, error) {
+	if t < 0 || int(t) >= len(topologyNames) {
+		return nil, fmt.Errorf("nieznana topologia %d", int(t))
+	}
+	return []byte(t.String()), nil
+}
+
+func (t *Topology) UnmarshalText(text []byte) error {
+	parsed, err := ParseTopology(string(text))
+	if err != nil {
+		return err
+	}
+	*t = parsed
+	return nil
+}
+
+// Następna i poprzednia topologia w kolejności (do przełączania w menu)
+func (t Topology) Next() Topology {
+	return (t + 1) % Topology(len(topologyNames))
+}
+
+func (t Topology) Prev() Topology {
+	return (t + Topology(len(topologyNames)) - 1) % Topology(len(topologyNames))
+}
+
+// Sąsiedzi pola (x, y) w sąsiedztwie świata (Moore'a, von Neumanna lub
+// sześciokątnym) zgodnie z topologią. Przy planszy odbijającej pole za krawędzią zastępuje
+// jego lustrzane odbicie, więc pole przy krawędzi ma zawsze pełne
+// sąsiedztwo, a niektórzy sąsiedzi występują dwukrotnie – dzięki temu ruch
+// losowy nie omija brzegów.
+func (w *World) neighbors(x, y int) [][2]int {
+	around := w.around([2]int{x, y}, 1)
+	result := make([][2]int, 0, len(around))
+	for _, c := range around {
+		if n, ok := w.wrap(c[0], c[1]); ok {
+			result = append(result, n)
+		}
+	}
+	return result
+}
+
+// Przekłada współrzędne spoza planszy na pole planszy według topologii;
+// false, gdy na planszy ograniczonej pole nie istnieje
+func (w *World) wrap(x, y int) ([2]int, bool) {
+	switch w.Topology {
+	case TopologyTorus:
+		return [2]int{mod(x, w.Width), mod(y, w.Height)}, true
+	case TopologyReflective:
+		return [2]int{reflect(x, w.Width), reflect(y, w.Height)}, true
+	default:
+		return [2]int{x, y}, x >= 0 && x < w.Width && y >= 0 && y < w.Height
+	}
+}
+
+// Przesunięcie z a do b; na torusie najkrótsze, z uwzględnieniem zawinięcia
+func (w *World) delta(a, b [2]int) (dx, dy int) {
+	dx, dy = b[0]-a[0], b[1]-a[1]
+	if w.Topology == TopologyTorus {
+		dx = shortest(dx, w.Width)
+		dy = shortest(dy, w.Height)
+	}
+	return dx, dy
+}
+
+// Kopie pola b przesunięte o wymiary planszy – na torusie cel może być
+// bliżej „przez krawędź”; na innych topologiach tylko samo b
+func (w *World) images(b [2]int) [][2]int {
+	if w.Topology != TopologyTorus {
+		return [][2]int{b}
+	}
+	result := make([][2]int, 0, 9)
+	for _, dx := range []int{-w.Width, 0, w.Width} {
+		for _, dy := range []int{-w.Height, 0, w.Height} {
+			result = append(result, [2]int{b[0] + dx, b[1] + dy})
+		}
+	}
+	return result
+}
+
+func mod(a, n int) int {
+	return ((a % n) + n) % n
+}
+
+// Odbicie współrzędnej od krawędzi: -1 → 1, n → n-2
+func reflect(a, n int) int {
+	period := 2 * (n - 1)
+	a = mod(a, period)
+	if a >= n {
+		a = period - a
+	}
+	return a
+}
+
+// Najkrótsze przesunięcie na okręgu o obwodzie n
+func shortest(d, n int) int {
+	d = mod(d, n)
+	if d > n/2 {
+		d -= n
+	}
+	return d
+}
@@ -0,0 +1,134 @@
+package sim
+
+import (
+	"cmp"
+	"slices"
+	"testing"
+)
+
+// Pusta plansza 5x4 o podanej topologii i sąsiedztwie
+func topologyWorld(t Topology, n Neighborhood) *World {
+	w := NewWorld(5, 4, GrassTall, 0, 1)
+	w.Topology = t
+	w.Neighborhood = n
+	return w
+}
+
+// Pola posortowane, aby porównywać zbiory sąsiadów (z powtórzeniami)
+func sortedCells(cells [][2]int) [][2]int {
+	cells = slices.Clone(cells)
+	slices.SortFunc(cells, func(a, b [2]int) int {
+		return cmp.Or(cmp.Compare(a[1], b[1]), cmp.Compare(a[0], b[0]))
+	})
+	return cells
+}
+
+func TestWrapMapsCoordinatesOutsideTheBoard(t *testing.T) {
+	tests := []struct {
+		topology Topology
+		x, y     int
+		want     [2]int
+		ok       bool
+	}{
+		{TopologyBounded, 2, 1, [2]int{2, 1}, true},
+		{TopologyBounded, -1, 0, [2]int{-1, 0}, false},
+		{TopologyBounded, 4, 4, [2]int{4, 4}, false},
+
+		{TopologyTorus, 2, 1, [2]int{2, 1}, true},
+		{TopologyTorus, -1, 0, [2]int{4, 0}, true},
+		{TopologyTorus, 5, 0, [2]int{0, 0}, true},
+		{TopologyTorus, 0, -1, [2]int{0, 3}, true},
+		{TopologyTorus, 0, 4, [2]int{0, 0}, true},
+		{TopologyTorus, -1, -1, [2]int{4, 3}, true},
+		{TopologyTorus, 5, 4, [2]int{0, 0}, true},
+
+		{TopologyReflective, 2, 1, [2]int{2, 1}, true},
+		{TopologyReflective, -1, 0, [2]int{1, 0}, true},
+		{TopologyReflective, 5, 0, [2]int{3, 0}, true},
+		{TopologyReflective, 0, -1, [2]int{0, 1}, true},
+		{TopologyReflective, 0, 4, [2]int{0, 2}, true},
+		{TopologyReflective, -1, -1, [2]int{1, 1}, true},
+		{TopologyReflective, 5, 4, [2]int{3, 2}, true},
+		{TopologyReflective, -2, 6, [2]int{2, 0}, true},
+	}
+	for _, tt := range tests {
+		w := topologyWorld(tt.topology, NeighborhoodMoore)
+		got, ok := w.wrap(tt.x, tt.y)
+		if ok != tt.ok || ok && got != tt.want {
+			t.Errorf("%s: wrap(%d, %d) = %v, %v; oczekiwano %v, %v", tt.topology, tt.x, tt.y, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestReflect(t *testing.T) {
+	tests := []struct{ a, n, want int }{
+		{0, 5, 0}, {4, 5, 4},
+		{-1, 5, 1}, {-2, 5, 2}, {-4, 5, 4}, {-5, 5, 3},
+		{5, 5, 3}, {6, 5, 2}, {8, 5, 0}, {9, 5, 1},
+		{-1, 2, 1}, {2, 2, 0},
+	}
+	for _, tt := range tests {
+		if got := reflect(tt.a, tt.n); got != tt.want {
+			t.Errorf("reflect(%d, %d) = %d, oczekiwano %d", tt.a, tt.n, got, tt.want)
+		}
+	}
+}
+
+// Sąsiedzi pól w rogu i przy krawędzi planszy 5x4
+func TestNeighborsAtEdgesAndCorners(t *testing.T) {
+	tests := []struct {
+		topology     Topology
+		neighborhood Neighborhood
+		pos          [2]int
+		want         [][2]int
+	}{
+		{TopologyBounded, NeighborhoodMoore, [2]int{0, 0}, [][2]int{{1, 0}, {0, 1}, {1, 1}}},
+		{TopologyBounded, NeighborhoodMoore, [2]int{2, 3}, [][2]int{{1, 2}, {2, 2}, {3, 2}, {1, 3}, {3, 3}}},
+		{TopologyBounded, NeighborhoodVonNeumann, [2]int{4, 3}, [][2]int{{4, 2}, {3, 3}}},
+		{TopologyTorus, NeighborhoodMoore, [2]int{0, 0}, [][2]int{
+			{4, 3}, {0, 3}, {1, 3}, {4, 0}, {1, 0}, {4, 1}, {0, 1}, {1, 1}}},
+		{TopologyTorus, NeighborhoodMoore, [2]int{4, 3}, [][2]int{
+			{3, 2}, {4, 2}, {0, 2}, {3, 3}, {0, 3}, {3, 0}, {4, 0}, {0, 0}}},
+		{TopologyTorus, NeighborhoodVonNeumann, [2]int{0, 0}, [][2]int{{0, 3}, {4, 0}, {1, 0}, {0, 1}}},
+		// Pola za krawędzią zastępują ich odbicia, więc niektórzy sąsiedzi się powtarzają
+		{TopologyReflective, NeighborhoodMoore, [2]int{0, 0}, [][2]int{
+			{1, 1}, {0, 1}, {1, 1}, {1, 0}, {1, 0}, {1, 1}, {0, 1}, {1, 1}}},
+		{TopologyReflective, NeighborhoodMoore, [2]int{2, 0}, [][2]int{
+			{1, 1}, {2, 1}, {3, 1}, {1, 0}, {3, 0}, {1, 1}, {2, 1}, {3, 1}}},
+		{TopologyReflective, NeighborhoodVonNeumann, [2]int{4, 3}, [][2]int{{4, 2}, {3, 3}, {3, 3}, {4, 2}}},
+		// Siatka sześciokątna: wiersz parzysty ma sąsiadów z lewej strony wierszy obok
+		{TopologyBounded, NeighborhoodHex, [2]int{0, 0}, [][2]int{{1, 0}, {0, 1}}},
+		{TopologyTorus, NeighborhoodHex, [2]int{0, 0}, [][2]int{{4, 0}, {1, 0}, {4, 3}, {0, 3}, {4, 1}, {0, 1}}},
+		{TopologyTorus, NeighborhoodHex, [2]int{4, 3}, [][2]int{{3, 3}, {0, 3}, {4, 2}, {0, 2}, {4, 0}, {0, 0}}},
+	}
+	for _, tt := range tests {
+		w := topologyWorld(tt.topology, tt.neighborhood)
+		got := sortedCells(w.neighbors(tt.pos[0], tt.pos[1]))
+		if want := sortedCells(tt.want); !slices.Equal(got, want) {
+			t.Errorf("%s/%s: sąsiedzi %v = %v, oczekiwano %v", tt.topology, tt.neighborhood, tt.pos, got, want)
+		}
+	}
+}
+
+// Na torusie odległość liczona jest przez krawędź, na innych topologiach nie
+func TestDistanceAcrossEdges(t *testing.T) {
+	tests := []struct {
+		topology     Topology
+		neighborhood Neighborhood
+		a, b         [2]int
+		want         int
+	}{
+		{TopologyBounded, NeighborhoodMoore, [2]int{0, 0}, [2]int{4, 3}, 4},
+		{TopologyTorus, NeighborhoodMoore, [2]int{0, 0}, [2]int{4, 3}, 1},
+		{TopologyTorus, NeighborhoodVonNeumann, [2]int{0, 0}, [2]int{4, 3}, 2},
+		{TopologyTorus, NeighborhoodMoore, [2]int{0, 1}, [2]int{3, 1}, 2},
+		{TopologyReflective, NeighborhoodMoore, [2]int{0, 0}, [2]int{4, 3}, 4},
+		{TopologyTorus, NeighborhoodHex, [2]int{0, 0}, [2]int{4, 3}, 1},
+	}
+	for _, tt := range tests {
+		w := topologyWorld(tt.topology, tt.neighborhood)
+		if got := w.distance(tt.a, tt.b); got != tt.want {
+			t.Errorf("%s/%s: odległość %v–%v = %d, oczekiwano %d", tt.topology, tt.neighborhood, tt.a, tt.b, got, tt.want)
+		}
+	}
+}
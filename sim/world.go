@@ -0,0 +1,225 @@
+// Pakiet sim zawiera model symulacji królików i lisów: planszę, reguły
+// wzrostu trawy, ruchu, jedzenia i rozmnażania oraz zliczanie populacji.
+// Nie zależy od raylib ani gonum, dzięki czemu można go używać w narzędziach
+// bez grafiki.
+package sim
+
+import "math/rand/v2"
+
+type Cell struct {
+	ID                uint64  `json:"id,omitempty"` // identyfikator zwierzęcia (metryka w World.Animal); 0 = brak zwierzęcia
+	Ground            int     `json:"ground"`       // 0=brak trawy, 1=short, 2=medium, 3=tall, 6=przeszkoda
+	Animal            int     `json:"animal"`       // 0=empty, 4=rabbit, 5=fox
+	Energy            float64 `json:"energy"`
+	ReproduceCooldown int     `json:"reproduceCooldown"`
+	Age               int     `json:"age"`
+}
+
+// Statystyki populacji po jednej turze
+type Population struct {
+	Turn    int `json:"turn"`
+	Rabbits int `json:"rabbits"`
+	Foxes   int `json:"foxes"`
+
+	// Liczba pól z trawą w danym stadium
+	GrassShort  int `json:"grassShort"`
+	GrassMedium int `json:"grassMedium"`
+	GrassTall   int `json:"grassTall"`
+
+	// Narodziny i zgony w tej turze
+	RabbitBirths int `json:"rabbitBirths"`
+	FoxBirths    int `json:"foxBirths"`
+	RabbitDeaths int `json:"rabbitDeaths"`
+	FoxDeaths    int `json:"foxDeaths"`
+
+	// Średnie po żyjących zwierzętach (0, gdy gatunek wyginął)
+	RabbitAvgEnergy float64 `json:"rabbitAvgEnergy"`
+	FoxAvgEnergy    float64 `json:"foxAvgEnergy"`
+	RabbitAvgAge    float64 `json:"rabbitAvgAge"`
+	FoxAvgAge       float64 `json:"foxAvgAge"`
+}
+
+const (
+	Empty       = 0
+	GrassShort  = 1
+	GrassMedium = 2
+	GrassTall   = 3
+	Rabbit      = 4
+	Fox         = 5
+	Obstacle    = 6 // podłoże, na które nie wchodzą zwierzęta i na którym nie rośnie trawa
+)
+
+type World struct {
+	Grid         [][]Cell
+	Width        int
+	Height       int
+	MaxGrass     int
+	GrowthRate   float64
+	Seed         uint64
+	Conflict     ConflictPolicy // rozstrzyganie sporów o to samo pole
+	Topology     Topology       // zachowanie krawędzi planszy
+	Neighborhood Neighborhood   // kształt sąsiedztwa i pola widzenia
+	Obstacles    float64        // odsetek pól zamienianych w przeszkody przy Initialize
+
+	// Parametry gatunków: energia, progi rozmnażania, pożywienie
+	Rabbit SpeciesParams
+	Fox    SpeciesParams
+
+	Turn    int          // liczba wykonanych tur
+	History []Population // statystyki po każdej turze; History[i] to stan po turze i+1
+
+	stats   Population     // narodziny i zgony od ostatniej tury: zmiany między turami i bieżąca tura
+	animals []AnimalRecord // metryki wszystkich zwierząt; animals[i] ma ID i+1
+
+	observers []func(Event)                // odbiorcy zdarzeń (Subscribe); nie przechodzą do kopii
+	trace     func(id uint64, rule string) // podgląd decyzji zwierząt (Inspect); nie przechodzi do kopii
+
+	// Własny generator świata; wszystkie reguły losują tylko z niego
+	pcg *rand.PCG
+	rng *rand.Rand
+}
+
+func NewWorld(width, height, maxGrass int, growthRate float64, seed uint64) *World {
+	grid := make([][]Cell, height)
+	for i := range grid {
+		grid[i] = make([]Cell, width)
+		for j := range grid[i] {
+			grid[i][j] = Cell{Ground: Empty, Animal: Empty}
+		}
+	}
+	pcg := rand.NewPCG(seed, 0)
+	return &World{
+		Grid:       grid,
+		Width:      width,
+		Height:     height,
+		MaxGrass:   maxGrass,
+		GrowthRate: growthRate,
+		Seed:       seed,
+		Rabbit:     DefaultRabbitParams(),
+		Fox:        DefaultFoxParams(),
+		pcg:        pcg,
+		rng:        rand.New(pcg),
+	}
+}
+
+// Inicjalizacja planszy z losowym rozmieszczeniem trawy, przeszkód (gdy
+// Obstacles > 0), królików i lisów
+func (w *World) Initialize(rabbitCount, foxCount int) {
+	for y := 0; y < w.Height; y++ {
+		for x := 0; x < w.Width; x++ {
+			r := w.rng.Float64()
+			if r < 0.33 {
+				w.Grid[y][x].Ground = GrassShort
+			} else if r < 0.66 {
+				w.Grid[y][x].Ground = GrassMedium
+			} else {
+				w.Grid[y][x].Ground = GrassTall
+			}
+			w.Grid[y][x].Ground = min(w.Grid[y][x].Ground, w.maxGrassStage())
+		}
+	}
+
+	if w.Obstacles > 0 {
+		for y := 0; y < w.Height; y++ {
+			for x := 0; x < w.Width; x++ {
+				if w.rng.Float64() < w.Obstacles {
+					w.Grid[y][x].Ground = Obstacle
+				}
+			}
+		}
+	}
+
+	for i := 0; i < rabbitCount; i++ {
+		x, y := w.randomPassable()
+		w.Grid[y][x].Animal = Rabbit
+		w.Grid[y][x].Energy = w.Rabbit.InitialEnergy
+	}
+
+	for i := 0; i < foxCount; i++ {
+		x, y := w.randomPassable()
+		w.Grid[y][x].Animal = Fox
+		w.Grid[y][x].Energy = w.Fox.InitialEnergy
+	}
+	// Identyfikatory dopiero po rozmieszczeniu, bo zwierzę może trafić na zajęte pole
+	w.registerUnnamed()
+}
+
+// Losowe pole bez przeszkody; gdy przeszkody zajmują całą planszę, dowolne pole
+func (w *World) randomPassable() (int, int) {
+	for range w.Width * w.Height * 4 {
+		x, y := w.rng.IntN(w.Width), w.rng.IntN(w.Height)
+		if w.Grid[y][x].Ground != Obstacle {
+			return x, y
+		}
+	}
+	return w.rng.IntN(w.Width), w.rng.IntN(w.Height)
+}
+
+// Czy zwierzę może wejść na pole: bez zwierzęcia i bez przeszkody
+func (w *World) free(pos [2]int) bool {
+	c := w.Grid[pos[1]][pos[0]]
+	return c.Animal == Empty && c.Ground != Obstacle
+}
+
+// Czy na polu rośnie trawa
+func isGrass(ground int) bool {
+	return ground >= GrassShort && ground <= GrassTall
+}
+
+// Kopia planszy
+func (w *World) copyGrid() [][]Cell {
+	newGrid := make([][]Cell, w.Height)
+	for y := 0; y < w.Height; y++ {
+		newGrid[y] = make([]Cell, w.Width)
+		copy(newGrid[y], w.Grid[y])
+	}
+	return newGrid
+}
+
+func (w *World) Copy() *World {
+	// Kopia dostaje własny generator w tym samym stanie co oryginał
+	pcg := *w.pcg
+	return &World{
+		Grid:         w.copyGrid(),
+		Width:        w.Width,
+		Height:       w.Height,
+		MaxGrass:     w.MaxGrass,
+		GrowthRate:   w.GrowthRate,
+		Seed:         w.Seed,
+		Conflict:     w.Conflict,
+		Topology:     w.Topology,
+		Neighborhood: w.Neighborhood,
+		Obstacles:    w.Obstacles,
+
+		Rabbit: w.Rabbit,
+		Fox:    w.Fox,
+
+		Turn:  w.Turn,
+		stats: w.stats,
+		// Historia jest tylko dopisywana, więc kopia może współdzielić tablicę;
+		// obcięta pojemność sprawia, że dopisanie do kopii nie nadpisze oryginału
+		History: w.History[:len(w.History):len(w.History)],
+		// Metryki zmieniają się przy śmierci zwierzęcia, więc kopia ma własne
+		animals: append([]AnimalRecord(nil), w.animals...),
+		pcg:     &pcg,
+		rng:     rand.New(&pcg),
+	}
+}
+
+// Najwyższe stadium trawy dopuszczone przez MaxGrass
+func (w *World) maxGrassStage() int {
+	return max(GrassShort, min(w.MaxGrass, GrassTall))
+}
+
+// Zlicza zwierzęta na planszy według gatunku
+func CountAnimals(w *World) map[int]int {
+	counts := make(map[int]int)
+	for y := 0; y < w.Height; y++ {
+		for x := 0; x < w.Width; x++ {
+			if w.Grid[y][x].Animal != Empty {
+				counts[w.Grid[y][x].Animal]++
+			}
+		}
+	}
+	return counts
+}
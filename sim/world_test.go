@@ -0,0 +1,72 @@
+package sim_test
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"example.com/mod/sim"
+)
+
+// Wykonuje n tur i zwraca historię populacji świata
+func runTurns(w *sim.World, n int) []sim.Population {
+	for range n {
+		w.Step()
+	}
+	return w.History
+}
+
+// Te same parametry i to samo ziarno dają identyczną historię populacji,
+// a inne ziarno – inną
+func TestSameParamsGiveIdenticalHistory(t *testing.T) {
+	for name, p := range snapshotTestParams() {
+		t.Run(name, func(t *testing.T) {
+			a := runTurns(newTestWorld(t, p), 300)
+			b := runTurns(newTestWorld(t, p), 300)
+			if len(a) != 300 {
+				t.Fatalf("historia ma %d tur, oczekiwano 300", len(a))
+			}
+			if !reflect.DeepEqual(a, b) {
+				for i := range a {
+					if a[i] != b[i] {
+						t.Fatalf("historie różnią się od tury %d: %+v i %+v", a[i].Turn, a[i], b[i])
+					}
+				}
+			}
+
+			other := p
+			other.Seed++
+			if reflect.DeepEqual(a, runTurns(newTestWorld(t, other), 300)) {
+				t.Fatal("inne ziarno dało identyczną historię")
+			}
+		})
+	}
+}
+
+// Zrzut zrobiony w trakcie przebiegu i wczytany daje dalej tę samą historię
+// co przebieg bez przerwy
+func TestSnapshotMidRunGivesSameHistory(t *testing.T) {
+	for name, p := range snapshotTestParams() {
+		t.Run(name, func(t *testing.T) {
+			want := runTurns(newTestWorld(t, p), 300)
+
+			first := newTestWorld(t, p)
+			runTurns(first, 120)
+			var buf bytes.Buffer
+			if err := sim.WriteSnapshotBinary(&buf, first.Snapshot()); err != nil {
+				t.Fatalf("zapis: %v", err)
+			}
+			s, err := sim.ReadSnapshot(&buf)
+			if err != nil {
+				t.Fatalf("odczyt: %v", err)
+			}
+			resumed, err := sim.FromSnapshot(s)
+			if err != nil {
+				t.Fatalf("FromSnapshot: %v", err)
+			}
+			if got := runTurns(resumed, 180); !reflect.DeepEqual(got, want) {
+				t.Fatalf("historia po wznowieniu różni się od przebiegu bez przerwy")
+			}
+		})
+	}
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"example.com/mod/batch"
+)
+
+// Uruchamia przegląd parametrów z pliku specPath i zapisuje tabelę podsumowań do outPath
+func RunSweep(specPath, outPath string, workers int) error {
+	spec, combos, err := batch.LoadSpec(specPath)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Kombinacje: %d  Ziarna na kombinację: %d  Tury: %d\n", len(combos), spec.Seeds, spec.MaxTurns)
+
+	summaries := batch.Run(spec, combos, workers, func(done, total int) {
+		fmt.Fprintf(os.Stderr, "\rPrzebiegi: %d/%d", done, total)
+		if done == total {
+			fmt.Fprintln(os.Stderr)
+		}
+	})
+
+	f, err := os.Create(outPath)
+	if err != nil {
+		return fmt.Errorf("zapis podsumowania: %w", err)
+	}
+	if err := batch.WriteSummaryCSV(f, spec.Names(), summaries); err != nil {
+		f.Close()
+		return fmt.Errorf("zapis podsumowania: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("zapis podsumowania: %w", err)
+	}
+	fmt.Printf("Podsumowanie zapisano w %s\n", outPath)
+	return nil
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"errors"
+	"os"
+
+	"example.com/mod/sim"
+	"example.com/mod/tui"
+)
+
+// Uruchamia symulację w terminalu (bez raylib) i zapisuje wyniki jak tryb bez okna
+func RunTUI(params sim.Params, opts RunOptions) error {
+	world, params, err := createWorld(params, opts.LoadPath)
+	if err != nil {
+		return err
+	}
+
+	history, err := openHistoryOutput(opts)
+	if err != nil {
+		return err
+	}
+	defer history.Close()
+	history.Attach(world)
+	if err := history.Write(world.History...); err != nil {
+		return err
+	}
+
+	runner := sim.NewRunner(world, opts.TPS)
+	var writeErr error
+	runner.OnStep = func(w *sim.World, pop sim.Population) {
+		if err := history.Write(pop); err != nil && writeErr == nil {
+			writeErr = err
+		}
+	}
+	final, err := tui.Run(runner, os.Stdin, os.Stdout)
+	if err != nil {
+		return err
+	}
+	if final == nil {
+		return errors.New("symulacja zakończyła się bez stanu świata do zapisania")
+	}
+	if writeErr != nil {
+		return writeErr
+	}
+	if err := history.Close(); err != nil {
+		return err
+	}
+	return saveResults(final, params, opts)
+}
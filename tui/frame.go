@@ -0,0 +1,112 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"example.com/mod/sim"
+)
+
+// Kolory tła podłoża w palecie 256 kolorów ANSI
+var groundColors = map[int]int{
+	sim.Empty:       180,
+	sim.GrassShort:  150,
+	sim.GrassMedium: 70,
+	sim.GrassTall:   28,
+	sim.Obstacle:    240,
+}
+
+// Znaki i kolory zwierząt
+const (
+	rabbitGlyph = "●"
+	foxGlyph    = "▲"
+	rabbitColor = 231
+	foxColor    = 202
+)
+
+// Wiersze ekranu zajęte przez nagłówek, wykresy i pomoc
+const chromeRows = 5
+
+// Stan widoku: przesunięcie planszy (w polach), gdy nie mieści się w terminalu
+type view struct {
+	x, y int
+}
+
+// Składa klatkę: nagłówek ze stanem, fragment planszy mieszczący się w
+// terminalu o rozmiarze rows x cols, wykresy liczebności i pomoc. Pole
+// zajmuje dwie kolumny; na siatce sześciokątnej co drugi wiersz jest
+// przesunięty o jedną kolumnę.
+func frame(w *sim.World, state sim.RunnerState, v view, rows, cols int) string {
+	var b strings.Builder
+	b.WriteString("\x1b[H")
+
+	counts := sim.CountAnimals(w)
+	mode := fmt.Sprintf("tempo %.3g tur/s", state.TPS)
+	switch {
+	case state.Paused:
+		mode = "PAUZA"
+	case state.FastForward:
+		mode = "PRZEWIJANIE"
+	}
+	line(&b, fmt.Sprintf("Tura %d  Króliki %d  Lisy %d  Ziarno %d  [%s]", w.Turn, counts[sim.Rabbit], counts[sim.Fox], w.Seed, mode), cols)
+
+	visW, visH := (cols-1)/2, max(1, rows-chromeRows)
+	for y := v.y; y < min(w.Height, v.y+visH); y++ {
+		if w.Neighborhood == sim.NeighborhoodHex && y&1 == 1 {
+			b.WriteString(" ")
+		}
+		for x := v.x; x < min(w.Width, v.x+visW); x++ {
+			c := w.Grid[y][x]
+			fmt.Fprintf(&b, "\x1b[48;5;%dm", groundColors[c.Ground])
+			switch c.Animal {
+			case sim.Rabbit:
+				fmt.Fprintf(&b, "\x1b[38;5;%dm%s ", rabbitColor, rabbitGlyph)
+			case sim.Fox:
+				fmt.Fprintf(&b, "\x1b[38;5;%dm%s ", foxColor, foxGlyph)
+			default:
+				b.WriteString("  ")
+			}
+		}
+		b.WriteString("\x1b[0m\x1b[K\r\n")
+	}
+	// Czyści wiersze pod planszą, jeśli terminal jest wyższy od planszy
+	for y := min(w.Height, v.y+visH) - v.y; y < visH; y++ {
+		b.WriteString("\x1b[K\r\n")
+	}
+
+	rabbits := make([]int, len(w.History))
+	foxes := make([]int, len(w.History))
+	for i, p := range w.History {
+		rabbits[i], foxes[i] = p.Rabbits, p.Foxes
+	}
+	width := max(1, cols-12)
+	fmt.Fprintf(&b, "Króliki \x1b[38;5;%dm%s\x1b[0m\x1b[K\r\n", rabbitColor, sparkline(rabbits, width))
+	fmt.Fprintf(&b, "Lisy    \x1b[38;5;%dm%s\x1b[0m\x1b[K\r\n", foxColor, sparkline(foxes, width))
+	line(&b, "spacja pauza  n tura  +/- tempo  f przewijanie  hjkl przesuwanie  q wyjście", cols)
+	return b.String()
+}
+
+// Dopisuje wiersz tekstu obcięty do szerokości terminala
+func line(b *strings.Builder, text string, cols int) {
+	if r := []rune(text); len(r) > cols {
+		text = string(r[:cols])
+	}
+	b.WriteString(text)
+	b.WriteString("\x1b[K\r\n")
+}
+
+var sparkBlocks = []rune("▁▂▃▄▅▆▇█")
+
+// Wykres ostatnich width wartości z blokami o wysokości proporcjonalnej do wartości
+func sparkline(values []int, width int) string {
+	values = values[max(0, len(values)-width):]
+	top := 1
+	for _, v := range values {
+		top = max(top, v)
+	}
+	out := make([]rune, len(values))
+	for i, v := range values {
+		out[i] = sparkBlocks[v*(len(sparkBlocks)-1)/top]
+	}
+	return string(out)
+}
@@ -0,0 +1,43 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// Obsługa terminala przez polecenie stty, aby nie zależeć od bibliotek
+// spoza biblioteki standardowej (działa na Linuksie i macOS, także przez SSH)
+
+// Przełącza terminal w tryb surowy bez echa (klawisze bez Enter) i zwraca
+// funkcję przywracającą poprzednie ustawienia
+func rawMode(f *os.File) (restore func(), err error) {
+	state, err := stty(f, "-g")
+	if err != nil {
+		return nil, fmt.Errorf("odczyt ustawień terminala: %w", err)
+	}
+	if _, err := stty(f, "raw", "-echo"); err != nil {
+		return nil, fmt.Errorf("tryb surowy terminala: %w", err)
+	}
+	return func() { stty(f, strings.TrimSpace(state)) }, nil
+}
+
+// Rozmiar terminala w wierszach i kolumnach; 24x80, gdy nie da się go odczytać
+func size(f *os.File) (rows, cols int) {
+	out, err := stty(f, "size")
+	if err != nil {
+		return 24, 80
+	}
+	if _, err := fmt.Sscan(out, &rows, &cols); err != nil || rows <= 0 || cols <= 0 {
+		return 24, 80
+	}
+	return rows, cols
+}
+
+func stty(f *os.File, args ...string) (string, error) {
+	cmd := exec.Command("stty", args...)
+	cmd.Stdin = f
+	out, err := cmd.Output()
+	return string(out), err
+}
@@ -0,0 +1,121 @@
+// Pakiet tui wyświetla symulację w terminalu znakami Unicode i kolorami
+// ANSI, np. przez SSH na maszynie bez ekranu. Korzysta z tego samego
+// sterownika symulacji (sim.Runner) co tryb okienkowy i nie zależy od raylib.
+package tui
+
+import (
+	"os"
+	"time"
+
+	"example.com/mod/sim"
+)
+
+// Odświeżanie ekranu najwyżej co tyle, niezależnie od tempa symulacji
+const redrawInterval = 50 * time.Millisecond
+
+// Co ile sprawdzany jest rozmiar terminala
+const resizeInterval = time.Second
+
+// Zmiana tempa klawiszami +/- (mnożnik tur na sekundę)
+const tpsFactor = 1.5
+
+// Prowadzi symulację runnera i wyświetla ją w terminalu in/out do naciśnięcia
+// q (albo Ctrl+C) lub, po wyginięciu zwierząt, dowolnego klawisza.
+// Zwraca ostatni stan świata (nil tylko przy błędzie).
+func Run(r *sim.Runner, in, out *os.File) (*sim.World, error) {
+	restore, err := rawMode(in)
+	if err != nil {
+		return nil, err
+	}
+	defer restore()
+	// Ukrycie kursora i wyczyszczenie ekranu; na końcu przywrócenie kursora
+	out.WriteString("\x1b[?25l\x1b[2J")
+	defer out.WriteString("\x1b[0m\x1b[?25h\r\n")
+
+	keys := make(chan byte)
+	go func() {
+		buf := make([]byte, 1)
+		for {
+			if n, err := in.Read(buf); err != nil {
+				close(keys)
+				return
+			} else if n == 1 {
+				keys <- buf[0]
+			}
+		}
+	}()
+
+	stop := make(chan struct{})
+	go r.Run(stop)
+	var world *sim.World
+	// Zatrzymuje symulację; ostatni stan wysłany przy zatrzymaniu staje się wynikiem
+	quit := func() {
+		close(stop)
+		for w := range r.Updates() {
+			world = w
+		}
+	}
+
+	rows, cols := size(in)
+	var v view
+	dirty := false
+	redraw := time.NewTicker(redrawInterval)
+	defer redraw.Stop()
+	resize := time.NewTicker(resizeInterval)
+	defer resize.Stop()
+	for {
+		select {
+		case w, ok := <-r.Updates():
+			if !ok {
+				// Zwierzęta wyginęły: ostatnia klatka zostaje do naciśnięcia klawisza
+				out.WriteString(frame(world, r.State(), v, rows, cols) + "Wszystkie zwierzęta wyginęły – naciśnij dowolny klawisz\x1b[K")
+				<-keys
+				return world, nil
+			}
+			world, dirty = w, true
+		case <-resize.C:
+			if nr, nc := size(in); nr != rows || nc != cols {
+				rows, cols, dirty = nr, nc, true
+				out.WriteString("\x1b[2J")
+			}
+		case <-redraw.C:
+			if dirty && world != nil {
+				out.WriteString(frame(world, r.State(), v, rows, cols))
+				dirty = false
+			}
+		case k, ok := <-keys:
+			if !ok {
+				quit()
+				return world, nil
+			}
+			switch k {
+			case 'q', 3: // 3 = Ctrl+C (w trybie surowym nie wysyła sygnału)
+				quit()
+				return world, nil
+			case ' ':
+				r.TogglePause()
+			case 'n':
+				r.Step(1)
+			case '+', '=':
+				r.SetTPS(r.State().TPS * tpsFactor)
+			case '-':
+				r.SetTPS(r.State().TPS / tpsFactor)
+			case 'f':
+				r.SetFastForward(!r.State().FastForward)
+			case 'h':
+				v.x = max(0, v.x-4)
+			case 'l':
+				v.x += 4
+			case 'k':
+				v.y = max(0, v.y-2)
+			case 'j':
+				v.y += 2
+			}
+			if world != nil {
+				v.x = max(0, min(v.x, world.Width-(cols-1)/2))
+				v.y = max(0, min(v.y, world.Height-(rows-chromeRows)))
+			}
+			dirty = true
+		}
+	}
+}
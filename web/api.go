@@ -0,0 +1,402 @@
+package web
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+
+	"example.com/mod/batch"
+	"example.com/mod/sim"
+)
+
+// API sterowania dla skryptów: wszystkie odpowiedzi i treści żądań to JSON,
+// błędy mają postać {"error": "..."}. Zmiany świata wykonywane są między
+// turami przez Runner.Do, a odczyty (Runner.View) widzą stan dokładnie po
+// ostatniej zmianie i nie wysyłają przeglądarkom nowego stanu.
+
+// Stan symulacji zwracany przez /api/state i polecenia sterujące
+type apiState struct {
+	Turn         int              `json:"turn"`
+	Width        int              `json:"width"`
+	Height       int              `json:"height"`
+	Topology     sim.Topology     `json:"topology"`
+	Neighborhood sim.Neighborhood `json:"neighborhood"`
+	Paused       bool             `json:"paused"`
+	FastForward  bool             `json:"fastForward"`
+	TPS          float64          `json:"tps"`
+	Ended        bool             `json:"ended"` // symulacja się zakończyła; zmiany nie są możliwe
+	Population   sim.Population   `json:"population"`
+}
+
+// Żyjące zwierzę na planszy
+type apiAnimal struct {
+	ID      uint64  `json:"id"`
+	Species string  `json:"species"`
+	X       int     `json:"x"`
+	Y       int     `json:"y"`
+	Energy  float64 `json:"energy"`
+	Age     int     `json:"age"`
+}
+
+var speciesNames = map[int]string{sim.Rabbit: "rabbit", sim.Fox: "fox"}
+
+func (s *Server) apiRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /api/state", s.handleState)
+	mux.HandleFunc("GET /api/stats", s.handleStats)
+	mux.HandleFunc("GET /api/history", s.handleHistory)
+	mux.HandleFunc("POST /api/pause", s.handlePause(true))
+	mux.HandleFunc("POST /api/resume", s.handlePause(false))
+	mux.HandleFunc("POST /api/step", s.handleStep)
+	mux.HandleFunc("GET /api/params", s.handleParams)
+	mux.HandleFunc("PATCH /api/params", s.handleSetParams)
+	mux.HandleFunc("GET /api/animals", s.handleAnimals)
+	mux.HandleFunc("POST /api/animals", s.handlePlaceAnimal)
+	mux.HandleFunc("DELETE /api/animals/{id}", s.handleRemoveAnimal)
+	mux.HandleFunc("GET /api/snapshot", s.handleSnapshot)
+	mux.HandleFunc("POST /api/snapshot", s.handleSaveSnapshot)
+}
+
+// Odczytuje bieżący świat runnera między turami; po zakończeniu symulacji –
+// ostatni stan (ended = true). f nie może zmieniać świata.
+func (s *Server) view(f func(w *sim.World, ended bool)) {
+	if s.runner.View(func(w *sim.World) { f(w, false) }) {
+		return
+	}
+	f(s.World(), true)
+}
+
+func (s *Server) state(w *sim.World, ended bool) apiState {
+	control := s.runner.State()
+	return apiState{
+		Turn:         w.Turn,
+		Width:        w.Width,
+		Height:       w.Height,
+		Topology:     w.Topology,
+		Neighborhood: w.Neighborhood,
+		Paused:       control.Paused,
+		FastForward:  control.FastForward,
+		TPS:          control.TPS,
+		Ended:        ended,
+		Population:   w.Census(),
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, format string, args ...any) {
+	writeJSON(w, status, map[string]string{"error": fmt.Sprintf(format, args...)})
+}
+
+// Odczytuje treść żądania JSON do v; pusta treść zostawia v bez zmian,
+// a nieznane pola są błędem
+func decodeJSON(data []byte, v any) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("nieprawidłowy JSON: %w", err)
+	}
+	return nil
+}
+
+func readJSON(r *http.Request, v any) error {
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		return err
+	}
+	return decodeJSON(data, v)
+}
+
+func (s *Server) handleState(w http.ResponseWriter, r *http.Request) {
+	var st apiState
+	s.view(func(world *sim.World, ended bool) { st = s.state(world, ended) })
+	writeJSON(w, http.StatusOK, st)
+}
+
+// Statystyki przebiegu z historii populacji (jak w trybie wsadowym)
+func (s *Server) handleStats(w http.ResponseWriter, r *http.Request) {
+	var stats batch.RunStats
+	s.view(func(world *sim.World, ended bool) { stats = batch.Analyze(world.History) })
+	writeJSON(w, http.StatusOK, stats)
+}
+
+func (s *Server) handleHistory(w http.ResponseWriter, r *http.Request) {
+	var history []sim.Population
+	s.view(func(world *sim.World, ended bool) { history = slices.Clone(world.History) })
+	if history == nil {
+		history = []sim.Population{}
+	}
+	writeJSON(w, http.StatusOK, history)
+}
+
+func (s *Server) handlePause(paused bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		s.runner.SetPaused(paused)
+		s.update(func() {})
+		s.handleState(w, r)
+	}
+}
+
+// Wstrzymuje symulację, wykonuje n tur ({"n": n}, domyślnie 1) i zwraca
+// stan po nich; odpowiedź przychodzi dopiero po wykonaniu tur
+func (s *Server) handleStep(w http.ResponseWriter, r *http.Request) {
+	req := struct {
+		N int `json:"n"`
+	}{N: 1}
+	if err := readJSON(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, "%v", err)
+		return
+	}
+	if req.N < 1 {
+		writeError(w, http.StatusBadRequest, "n: musi wynosić co najmniej 1 (jest %d)", req.N)
+		return
+	}
+
+	// Włączenie pauzy kasuje zaległe tury, więc tylko gdy jej nie ma
+	if !s.runner.State().Paused {
+		s.runner.SetPaused(true)
+	}
+	var target int
+	if !s.runner.View(func(world *sim.World) { target = world.Turn + req.N }) {
+		writeError(w, http.StatusConflict, "symulacja się zakończyła")
+		return
+	}
+	s.runner.Step(req.N)
+	for {
+		world, ended, _, changed := s.latest()
+		if ended || world != nil && world.Turn >= target {
+			break
+		}
+		select {
+		case <-changed:
+		case <-r.Context().Done():
+			return
+		}
+	}
+	s.handleState(w, r)
+}
+
+func (s *Server) handleParams(w http.ResponseWriter, r *http.Request) {
+	var params sim.Params
+	s.view(func(world *sim.World, ended bool) { params = world.Params() })
+	writeJSON(w, http.StatusOK, params)
+}
+
+// Zmienia wybrane parametry: treść to fragment pliku parametrów, np.
+// {"growthRate": 0.2, "fox": {"preyEnergy": 15}}; pozostałe pola zostają
+func (s *Server) handleSetParams(w http.ResponseWriter, r *http.Request) {
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "%v", err)
+		return
+	}
+	var params sim.Params
+	ok := s.runner.Do(func(world *sim.World) {
+		p := world.Params()
+		if err = decodeJSON(data, &p); err == nil {
+			err = world.SetParams(p)
+		}
+		params = world.Params()
+	})
+	switch {
+	case !ok:
+		writeError(w, http.StatusConflict, "symulacja się zakończyła")
+	case err != nil:
+		writeError(w, http.StatusBadRequest, "%v", err)
+	default:
+		writeJSON(w, http.StatusOK, params)
+	}
+}
+
+func (s *Server) handleAnimals(w http.ResponseWriter, r *http.Request) {
+	animals := []apiAnimal{}
+	s.view(func(world *sim.World, ended bool) {
+		for y := 0; y < world.Height; y++ {
+			for x := 0; x < world.Width; x++ {
+				if c := world.Grid[y][x]; c.Animal != sim.Empty {
+					animals = append(animals, animalInfo(c, x, y))
+				}
+			}
+		}
+	})
+	writeJSON(w, http.StatusOK, animals)
+}
+
+func animalInfo(c sim.Cell, x, y int) apiAnimal {
+	return apiAnimal{
+		ID:      c.ID,
+		Species: speciesNames[c.Animal],
+		X:       x,
+		Y:       y,
+		Energy:  c.Energy,
+		Age:     c.Age,
+	}
+}
+
+// Stawia zwierzę: {"species": "rabbit" | "fox", "x": x, "y": y}. Jak przy
+// pędzlu postawienie to narodziny w następnej turze (zdarzenie i statystyki).
+func (s *Server) handlePlaceAnimal(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Species string `json:"species"`
+		X       int    `json:"x"`
+		Y       int    `json:"y"`
+	}
+	if err := readJSON(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, "%v", err)
+		return
+	}
+	species := sim.Empty
+	for animal, name := range speciesNames {
+		if name == req.Species {
+			species = animal
+		}
+	}
+	if species == sim.Empty {
+		writeError(w, http.StatusBadRequest, "species: nieznany gatunek %q (dozwolone: rabbit, fox)", req.Species)
+		return
+	}
+
+	var animal apiAnimal
+	inside, placed := false, false
+	ok := s.runner.Do(func(world *sim.World) {
+		inside = req.X >= 0 && req.X < world.Width && req.Y >= 0 && req.Y < world.Height
+		if placed = world.PlaceAnimal(req.X, req.Y, species); placed {
+			animal = animalInfo(world.Grid[req.Y][req.X], req.X, req.Y)
+		}
+	})
+	switch {
+	case !ok:
+		writeError(w, http.StatusConflict, "symulacja się zakończyła")
+	case !inside:
+		writeError(w, http.StatusBadRequest, "pole (%d, %d) leży poza planszą", req.X, req.Y)
+	case !placed:
+		writeError(w, http.StatusConflict, "pole (%d, %d) jest zajęte albo jest przeszkodą", req.X, req.Y)
+	default:
+		writeJSON(w, http.StatusCreated, animal)
+	}
+}
+
+// Usuwa żyjące zwierzę o podanym identyfikatorze; w zdarzeniach i statystykach
+// następnej tury to śmierć z przyczyną CauseRemoved
+func (s *Server) handleRemoveAnimal(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(r.PathValue("id"), 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "nieprawidłowy identyfikator %q", r.PathValue("id"))
+		return
+	}
+	removed := false
+	ok := s.runner.Do(func(world *sim.World) {
+		if pos, alive := world.Locate(id); alive {
+			removed = world.RemoveAnimal(pos[0], pos[1])
+		}
+	})
+	switch {
+	case !ok:
+		writeError(w, http.StatusConflict, "symulacja się zakończyła")
+	case !removed:
+		writeError(w, http.StatusNotFound, "brak żyjącego zwierzęcia %d", id)
+	default:
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// Pobiera zrzut bieżącego stanu: JSON albo (?format=binary) format binarny
+func (s *Server) handleSnapshot(w http.ResponseWriter, r *http.Request) {
+	format := r.URL.Query().Get("format")
+	if format != "" && format != "json" && format != "binary" {
+		writeError(w, http.StatusBadRequest, "format: nieznany format %q (dozwolone: json, binary)", format)
+		return
+	}
+	var world *sim.World
+	s.view(func(current *sim.World, ended bool) { world = current.Copy() })
+	if format == "binary" {
+		var buf bytes.Buffer
+		if err := sim.WriteSnapshotBinary(&buf, world.Snapshot()); err != nil {
+			writeError(w, http.StatusInternalServerError, "%v", err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Write(buf.Bytes())
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	sim.WriteSnapshotJSON(w, world.Snapshot())
+}
+
+// Zapisuje zrzut do nowego pliku w katalogu zrzutów serwera (SnapshotDir):
+// {"path": "t100.json"}. Dozwolona jest tylko nazwa pliku z rozszerzeniem
+// .json (JSON) albo .kls (format binarny); istniejący plik nie jest nadpisywany.
+func (s *Server) handleSaveSnapshot(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Path string `json:"path"`
+	}
+	if err := readJSON(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, "%v", err)
+		return
+	}
+	if s.SnapshotDir == "" {
+		writeError(w, http.StatusForbidden, "zapis zrzutów na serwerze jest wyłączony")
+		return
+	}
+	if !filepath.IsLocal(req.Path) || filepath.Base(req.Path) != req.Path {
+		writeError(w, http.StatusBadRequest, "path: %q musi być samą nazwą pliku w katalogu zrzutów", req.Path)
+		return
+	}
+	ext := filepath.Ext(req.Path)
+	if ext != ".json" && ext != ".kls" {
+		writeError(w, http.StatusBadRequest, "path: nieznane rozszerzenie %q (dozwolone: .json, .kls)", ext)
+		return
+	}
+
+	var world *sim.World
+	s.view(func(current *sim.World, ended bool) { world = current.Copy() })
+	path := filepath.Join(s.SnapshotDir, req.Path)
+	err := createSnapshot(path, world)
+	switch {
+	case errors.Is(err, fs.ErrExist):
+		writeError(w, http.StatusConflict, "plik %q już istnieje", req.Path)
+	case err != nil:
+		writeError(w, http.StatusInternalServerError, "%v", err)
+	default:
+		writeJSON(w, http.StatusOK, map[string]any{"path": path, "turn": world.Turn})
+	}
+}
+
+// Zapisuje zrzut świata do nowego pliku (błąd fs.ErrExist, gdy plik już
+// istnieje); .json = JSON, inne = format binarny
+func createSnapshot(path string, world *sim.World) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	bw := bufio.NewWriter(f)
+	if filepath.Ext(path) == ".json" {
+		err = sim.WriteSnapshotJSON(bw, world.Snapshot())
+	} else {
+		err = sim.WriteSnapshotBinary(bw, world.Snapshot())
+	}
+	if err != nil {
+		return err
+	}
+	if err := bw.Flush(); err != nil {
+		return err
+	}
+	return f.Close()
+}
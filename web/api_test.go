@@ -0,0 +1,296 @@
+package web_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"example.com/mod/sim"
+	"example.com/mod/web"
+)
+
+// Serwer API z wstrzymaną symulacją na domyślnych parametrach i jej świat
+// sprzed startu (do wyboru pól)
+type apiTest struct {
+	t      *testing.T
+	url    string
+	world  *sim.World
+	server *web.Server
+	stop   chan struct{}
+	done   chan struct{}
+}
+
+func newAPITest(t *testing.T, snapshotDir string) *apiTest {
+	t.Helper()
+	p := sim.DefaultParams()
+	p.Seed = 1
+	world := sim.NewWorldFromParams(p)
+	world.Initialize(p.Rabbits, p.Foxes)
+
+	runner := sim.NewRunner(world.Copy(), 10)
+	runner.SetPaused(true)
+	at := &apiTest{
+		t:      t,
+		world:  world,
+		server: web.NewServer(runner),
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	at.server.SnapshotDir = snapshotDir
+	go func() {
+		defer close(at.done)
+		at.server.Run(at.stop)
+	}()
+	ts := httptest.NewServer(at.server.Handler())
+	at.url = ts.URL
+	t.Cleanup(func() {
+		ts.Close()
+		at.end()
+	})
+	return at
+}
+
+// Kończy symulację i czeka, aż serwer oznaczy ją jako zakończoną
+func (at *apiTest) end() {
+	select {
+	case <-at.stop:
+	default:
+		close(at.stop)
+	}
+	<-at.done
+}
+
+// Wysyła żądanie z treścią body (pusta = bez treści); zwraca kod odpowiedzi
+// i, gdy v != nil, odczytuje do niego treść JSON
+func (at *apiTest) do(method, path, body string, v any) int {
+	at.t.Helper()
+	req, err := http.NewRequest(method, at.url+path, strings.NewReader(body))
+	if err != nil {
+		at.t.Fatal(err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		at.t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		at.t.Fatal(err)
+	}
+	if v != nil && resp.StatusCode < 300 {
+		if err := json.Unmarshal(data, v); err != nil {
+			at.t.Fatalf("%s %s: %v w odpowiedzi %s", method, path, err, data)
+		}
+	}
+	return resp.StatusCode
+}
+
+// Pierwsze puste pole planszy (bez zwierzęcia i przeszkody)
+func (at *apiTest) emptyCell() (int, int) {
+	for y := range at.world.Height {
+		for x := range at.world.Width {
+			if c := at.world.Grid[y][x]; c.Animal == sim.Empty && c.Ground != sim.Obstacle {
+				return x, y
+			}
+		}
+	}
+	at.t.Fatal("brak pustego pola")
+	return 0, 0
+}
+
+// Pierwsze pole ze zwierzęciem
+func (at *apiTest) animalCell() (int, int, sim.Cell) {
+	for y := range at.world.Height {
+		for x := range at.world.Width {
+			if c := at.world.Grid[y][x]; c.Animal != sim.Empty {
+				return x, y, c
+			}
+		}
+	}
+	at.t.Fatal("brak zwierzęcia")
+	return 0, 0, sim.Cell{}
+}
+
+func TestStep(t *testing.T) {
+	at := newAPITest(t, "")
+	var st struct {
+		Turn   int  `json:"turn"`
+		Paused bool `json:"paused"`
+	}
+	if code := at.do("POST", "/api/step", `{"n": 5}`, &st); code != http.StatusOK {
+		t.Fatalf("step 5: kod %d", code)
+	}
+	if st.Turn != 5 || !st.Paused {
+		t.Fatalf("po step 5: tura %d, pauza %v; oczekiwano 5 i pauzy", st.Turn, st.Paused)
+	}
+	if code := at.do("POST", "/api/step", "", &st); code != http.StatusOK || st.Turn != 6 {
+		t.Fatalf("step bez treści: kod %d, tura %d; oczekiwano 200 i tury 6", code, st.Turn)
+	}
+
+	for _, body := range []string{`{"n": 0}`, `{"n": -3}`, `{"turns": 2}`, `{"n": "dwa"}`, `{`} {
+		if code := at.do("POST", "/api/step", body, nil); code != http.StatusBadRequest {
+			t.Errorf("step %s: kod %d, oczekiwano 400", body, code)
+		}
+	}
+}
+
+func TestSetParams(t *testing.T) {
+	at := newAPITest(t, "")
+	var p sim.Params
+	if code := at.do("PATCH", "/api/params", `{"growthRate": 0.05, "fox": {"preyEnergy": 15}}`, &p); code != http.StatusOK {
+		t.Fatalf("zmiana parametrów: kod %d", code)
+	}
+	if p.GrowthRate != 0.05 || p.Fox.PreyEnergy != 15 || p.Rabbit != sim.DefaultRabbitParams() {
+		t.Fatalf("parametry po zmianie: %+v", p)
+	}
+
+	tests := map[string]string{
+		"nieznane pole":              `{"speed": 2}`,
+		"nieznane pole zagnieżdżone": `{"fox": {"speed": 2}}`,
+		"nieprawidłowa wartość":      `{"growthRate": 2}`,
+		"zły typ":                    `{"growthRate": "dużo"}`,
+		"wymiary":                    `{"width": 10}`,
+		"ziarno":                     `{"seed": 3}`,
+	}
+	for name, body := range tests {
+		if code := at.do("PATCH", "/api/params", body, nil); code != http.StatusBadRequest {
+			t.Errorf("%s: kod %d, oczekiwano 400", name, code)
+		}
+	}
+
+	// Odrzucone zmiany nie zostawiają śladu w parametrach
+	if at.do("GET", "/api/params", "", &p); p.GrowthRate != 0.05 || p.Width != 32 || p.Seed != 1 {
+		t.Fatalf("parametry po odrzuconych zmianach: %+v", p)
+	}
+}
+
+func TestPlaceAndRemoveAnimal(t *testing.T) {
+	at := newAPITest(t, "")
+	x, y := at.emptyCell()
+	var animal struct {
+		ID      uint64 `json:"id"`
+		Species string `json:"species"`
+		X, Y    int
+	}
+	body := fmt.Sprintf(`{"species": "fox", "x": %d, "y": %d}`, x, y)
+	if code := at.do("POST", "/api/animals", body, &animal); code != http.StatusCreated {
+		t.Fatalf("postawienie lisa: kod %d", code)
+	}
+	if animal.ID == 0 || animal.Species != "fox" || animal.X != x || animal.Y != y {
+		t.Fatalf("postawiony lis: %+v", animal)
+	}
+
+	tests := []struct {
+		name string
+		body string
+		want int
+	}{
+		{"zajęte pole", body, http.StatusConflict},
+		{"poza planszą", `{"species": "rabbit", "x": 32, "y": 0}`, http.StatusBadRequest},
+		{"ujemna współrzędna", `{"species": "rabbit", "x": 0, "y": -1}`, http.StatusBadRequest},
+		{"nieznany gatunek", fmt.Sprintf(`{"species": "wolf", "x": %d, "y": %d}`, x, y), http.StatusBadRequest},
+		{"nieznane pole", `{"species": "rabbit", "x": 0, "y": 0, "energy": 5}`, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		if code := at.do("POST", "/api/animals", tt.body, nil); code != tt.want {
+			t.Errorf("%s: kod %d, oczekiwano %d", tt.name, code, tt.want)
+		}
+	}
+
+	path := fmt.Sprintf("/api/animals/%d", animal.ID)
+	if code := at.do("DELETE", path, "", nil); code != http.StatusNoContent {
+		t.Fatalf("usunięcie lisa: kod %d", code)
+	}
+	if code := at.do("DELETE", path, "", nil); code != http.StatusNotFound {
+		t.Errorf("ponowne usunięcie: kod %d, oczekiwano 404", code)
+	}
+	if code := at.do("DELETE", "/api/animals/lis", "", nil); code != http.StatusBadRequest {
+		t.Errorf("nieprawidłowy identyfikator: kod %d, oczekiwano 400", code)
+	}
+	if code := at.do("POST", "/api/animals", body, nil); code != http.StatusCreated {
+		t.Errorf("postawienie na zwolnionym polu: kod %d, oczekiwano 201", code)
+	}
+}
+
+// Po zakończeniu symulacji odczyty pokazują stan końcowy, a zmiany są odrzucane
+func TestEndedSimulationRejectsChanges(t *testing.T) {
+	at := newAPITest(t, "")
+	at.end()
+
+	var st struct {
+		Ended bool `json:"ended"`
+	}
+	if code := at.do("GET", "/api/state", "", &st); code != http.StatusOK || !st.Ended {
+		t.Fatalf("stan: kod %d, ended %v; oczekiwano 200 i zakończenia", code, st.Ended)
+	}
+
+	x, y := at.emptyCell()
+	ax, ay, animal := at.animalCell()
+	tests := []struct{ method, path, body string }{
+		{"POST", "/api/step", `{"n": 1}`},
+		{"PATCH", "/api/params", `{"growthRate": 0.05}`},
+		{"POST", "/api/animals", fmt.Sprintf(`{"species": "rabbit", "x": %d, "y": %d}`, x, y)},
+		{"DELETE", fmt.Sprintf("/api/animals/%d", animal.ID), ""},
+	}
+	for _, tt := range tests {
+		if code := at.do(tt.method, tt.path, tt.body, nil); code != http.StatusConflict {
+			t.Errorf("%s %s: kod %d, oczekiwano 409", tt.method, tt.path, code)
+		}
+	}
+
+	var animals []struct{ X, Y int }
+	if at.do("GET", "/api/animals", "", &animals); len(animals) == 0 {
+		t.Fatal("brak zwierząt w stanie końcowym")
+	}
+	found := false
+	for _, a := range animals {
+		found = found || a.X == ax && a.Y == ay
+	}
+	if !found {
+		t.Errorf("zwierzę z (%d, %d) zniknęło po odrzuconym usunięciu", ax, ay)
+	}
+}
+
+func TestSaveSnapshot(t *testing.T) {
+	dir := t.TempDir()
+	at := newAPITest(t, filepath.Join(dir, "zrzuty"))
+
+	for _, name := range []string{"t0.json", "t0.kls"} {
+		body := fmt.Sprintf(`{"path": %q}`, name)
+		if code := at.do("POST", "/api/snapshot", body, nil); code != http.StatusOK {
+			t.Fatalf("zapis %s: kod %d", name, code)
+		}
+		w, err := sim.LoadSnapshot(filepath.Join(at.server.SnapshotDir, name))
+		if err != nil {
+			t.Fatalf("wczytanie %s: %v", name, err)
+		}
+		if w.Census() != at.world.Census() {
+			t.Errorf("%s: stan %+v, oczekiwano %+v", name, w.Census(), at.world.Census())
+		}
+		if code := at.do("POST", "/api/snapshot", body, nil); code != http.StatusConflict {
+			t.Errorf("nadpisanie %s: kod %d, oczekiwano 409", name, code)
+		}
+	}
+
+	outside := filepath.Join(dir, "poza.json")
+	for _, path := range []string{"../poza.json", outside, "pod/t0.json", "t0.txt", "t0", ""} {
+		body := fmt.Sprintf(`{"path": %q}`, path)
+		if code := at.do("POST", "/api/snapshot", body, nil); code != http.StatusBadRequest {
+			t.Errorf("ścieżka %q: kod %d, oczekiwano 400", path, code)
+		}
+	}
+	if _, err := os.Stat(outside); err == nil {
+		t.Errorf("zapisano zrzut poza katalogiem zrzutów: %s", outside)
+	}
+
+	at = newAPITest(t, "")
+	if code := at.do("POST", "/api/snapshot", `{"path": "t1.json"}`, nil); code != http.StatusForbidden {
+		t.Errorf("zapis bez katalogu zrzutów: kod %d, oczekiwano 403", code)
+	}
+}
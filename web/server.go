@@ -0,0 +1,127 @@
+// Pakiet web udostępnia podgląd symulacji w przeglądarce: stronę z planszą
+// rysowaną na canvasie, strumień stanu (Server-Sent Events) z pełną planszą,
+// a potem tylko zmienionymi polami, oraz sterowanie (pauza, tura, tempo)
+// wysyłane z powrotem do serwera. API JSON (/api/...) pozwala skryptom
+// sterować symulacją, zmieniać parametry i zwierzęta oraz zapisywać zrzuty.
+// Nie zależy od raylib ani cgo.
+package web
+
+import (
+	"embed"
+	"net/http"
+	"strconv"
+	"sync"
+
+	"example.com/mod/sim"
+)
+
+//go:embed static
+var static embed.FS
+
+// Zmiana tempa przyciskami (mnożnik tur na sekundę)
+const tpsFactor = 1.5
+
+// Server rozsyła kolejne stany świata z runnera wszystkim podłączonym
+// przeglądarkom i przekazuje runnerowi polecenia sterujące
+type Server struct {
+	// Katalog, w którym POST /api/snapshot tworzy zrzuty; pusty wyłącza zapis
+	SnapshotDir string
+
+	runner *sim.Runner
+
+	mu      sync.Mutex
+	world   *sim.World    // najnowszy stan (kopia z runnera, tylko do odczytu)
+	ended   bool          // symulacja się zakończyła
+	version int           // numer zmiany stanu świata lub sterowania
+	changed chan struct{} // zamykany przy każdej zmianie, aby obudzić strumienie
+}
+
+// Tworzy serwer dla runnera; symulację uruchamia Run
+func NewServer(r *sim.Runner) *Server {
+	return &Server{runner: r, changed: make(chan struct{})}
+}
+
+// Prowadzi symulację runnera do zamknięcia stop albo wyginięcia zwierząt,
+// udostępniając każdy nowy stan strumieniom. Po zakończeniu serwer dalej
+// pokazuje ostatni stan.
+func (s *Server) Run(stop <-chan struct{}) {
+	go s.runner.Run(stop)
+	for w := range s.runner.Updates() {
+		s.update(func() { s.world = w })
+	}
+	s.update(func() { s.ended = true })
+}
+
+// Wprowadza zmianę stanu i budzi strumienie
+func (s *Server) update(change func()) {
+	s.mu.Lock()
+	change()
+	s.version++
+	close(s.changed)
+	s.changed = make(chan struct{})
+	s.mu.Unlock()
+}
+
+// Najnowszy stan świata, znacznik zakończenia, numer zmiany i kanał
+// zamykany przy następnej zmianie
+func (s *Server) latest() (*sim.World, bool, int, <-chan struct{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.world, s.ended, s.version, s.changed
+}
+
+// Ostatni stan świata (nil przed pierwszym stanem z runnera)
+func (s *Server) World() *sim.World {
+	w, _, _, _ := s.latest()
+	return w
+}
+
+// Obsługa HTTP: strona (/), strumień stanu (/events), sterowanie z przycisków
+// strony (/control) i API JSON dla skryptów (/api/...)
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("GET /", http.FileServerFS(staticFS()))
+	mux.HandleFunc("GET /events", s.handleEvents)
+	mux.HandleFunc("POST /control", s.handleControl)
+	s.apiRoutes(mux)
+	return mux
+}
+
+// Sterowanie z przycisków strony: parametr action (pause, resume, toggle,
+// step, faster, slower, fast, normal) i opcjonalnie n – liczba tur dla step
+func (s *Server) handleControl(w http.ResponseWriter, r *http.Request) {
+	state := s.runner.State()
+	switch r.FormValue("action") {
+	case "pause":
+		s.runner.SetPaused(true)
+	case "resume":
+		s.runner.SetPaused(false)
+	case "toggle":
+		s.runner.TogglePause()
+	case "step":
+		n := 1
+		if v := r.FormValue("n"); v != "" {
+			parsed, err := strconv.Atoi(v)
+			if err != nil || parsed < 1 {
+				http.Error(w, "nieprawidłowa liczba tur", http.StatusBadRequest)
+				return
+			}
+			n = parsed
+		}
+		s.runner.Step(n)
+	case "faster":
+		s.runner.SetTPS(state.TPS * tpsFactor)
+	case "slower":
+		s.runner.SetTPS(state.TPS / tpsFactor)
+	case "fast":
+		s.runner.SetFastForward(true)
+	case "normal":
+		s.runner.SetFastForward(false)
+	default:
+		http.Error(w, "nieznana akcja", http.StatusBadRequest)
+		return
+	}
+	// Strumienie wysyłają nowy stan sterowania od razu
+	s.update(func() {})
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,141 @@
+package web
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"time"
+
+	"example.com/mod/sim"
+)
+
+// Najkrótszy odstęp między wiadomościami strumienia; przy szybszej symulacji
+// przeglądarka dostaje tylko najnowszy stan
+const streamInterval = 50 * time.Millisecond
+
+func staticFS() fs.FS {
+	sub, err := fs.Sub(static, "static")
+	if err != nil {
+		panic(err)
+	}
+	return sub
+}
+
+// Wiadomość strumienia: pełna plansza (Cells) albo zmienione pola (Changes)
+// wraz z liczebnościami i stanem sterowania
+type message struct {
+	Turn  int `json:"turn"`
+	Width int `json:"width,omitempty"`
+	// Wysokość, siatka i topologia tylko w pełnej planszy
+	Height   int    `json:"height,omitempty"`
+	Hex      bool   `json:"hex,omitempty"`
+	Topology string `json:"topology,omitempty"`
+
+	Cells   []int    `json:"cells,omitempty"`   // kody pól wierszami
+	Changes [][2]int `json:"changes,omitempty"` // pary [indeks pola, kod]
+
+	Rabbits int `json:"rabbits"`
+	Foxes   int `json:"foxes"`
+	Grass   int `json:"grass"` // liczba pól z trawą
+
+	Paused      bool    `json:"paused"`
+	FastForward bool    `json:"fastForward"`
+	TPS         float64 `json:"tps"`
+	Ended       bool    `json:"ended"`
+}
+
+// Kod pola: podłoże (0–3, 6 = przeszkoda) plus 8 dla królika albo 16 dla lisa
+func cellCode(c sim.Cell) int {
+	code := c.Ground
+	switch c.Animal {
+	case sim.Rabbit:
+		code += 8
+	case sim.Fox:
+		code += 16
+	}
+	return code
+}
+
+// Wiadomość ze stanem w; gdy prev ma te same wymiary, tylko ze zmienionymi polami
+func (s *Server) message(prev, w *sim.World, ended bool) (string, message) {
+	m := message{Turn: w.Turn, Ended: ended}
+	state := s.runner.State()
+	m.Paused, m.FastForward, m.TPS = state.Paused, state.FastForward, state.TPS
+	for y := 0; y < w.Height; y++ {
+		for x := 0; x < w.Width; x++ {
+			c := w.Grid[y][x]
+			switch c.Animal {
+			case sim.Rabbit:
+				m.Rabbits++
+			case sim.Fox:
+				m.Foxes++
+			}
+			if c.Ground >= sim.GrassShort && c.Ground <= sim.GrassTall {
+				m.Grass++
+			}
+		}
+	}
+
+	if prev == nil || prev.Width != w.Width || prev.Height != w.Height || prev.Neighborhood != w.Neighborhood {
+		m.Width, m.Height = w.Width, w.Height
+		m.Hex = w.Neighborhood == sim.NeighborhoodHex
+		m.Topology = w.Topology.String()
+		m.Cells = make([]int, 0, w.Width*w.Height)
+		for y := 0; y < w.Height; y++ {
+			for x := 0; x < w.Width; x++ {
+				m.Cells = append(m.Cells, cellCode(w.Grid[y][x]))
+			}
+		}
+		return "full", m
+	}
+	m.Changes = [][2]int{}
+	for y := 0; y < w.Height; y++ {
+		for x := 0; x < w.Width; x++ {
+			if code := cellCode(w.Grid[y][x]); code != cellCode(prev.Grid[y][x]) {
+				m.Changes = append(m.Changes, [2]int{y*w.Width + x, code})
+			}
+		}
+	}
+	return "diff", m
+}
+
+// Strumień Server-Sent Events: najpierw pełna plansza, potem zmiany
+func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "strumień nie jest obsługiwany", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+
+	var last *sim.World
+	sent := -1
+	for {
+		world, ended, version, changed := s.latest()
+		if world != nil && version != sent {
+			event, m := s.message(last, world, ended)
+			data, err := json.Marshal(m)
+			if err != nil {
+				return
+			}
+			if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, data); err != nil {
+				return
+			}
+			flusher.Flush()
+			last, sent = world, version
+		}
+
+		select {
+		case <-changed:
+		case <-r.Context().Done():
+			return
+		}
+		select {
+		case <-time.After(streamInterval):
+		case <-r.Context().Done():
+			return
+		}
+	}
+}